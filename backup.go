@@ -0,0 +1,706 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jdwalkerzhere/gator/internal/archive"
+	"github.com/jdwalkerzhere/gator/internal/database"
+)
+
+// exportArchive writes every user with their feeds, follows, folders, posts
+// and per-user state. Records are written parents first so a restore never
+// meets a reference it hasn't seen yet.
+func exportArchive(ctx context.Context, s *state, w io.Writer) error {
+	writer, err := archive.NewWriter(w)
+	if err != nil {
+		return err
+	}
+	counts := make(map[string]int)
+	write := func(recordType string, data any) error {
+		counts[recordType]++
+		return writer.Write(recordType, data)
+	}
+
+	// dump everything from one snapshot, so an agg ingesting or pruning
+	// meanwhile can't leave states referring to posts the archive lacks
+	tx, err := s.sqlDB.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	db := s.db.WithTx(tx)
+
+	users, err := db.DumpUsers(ctx)
+	if err != nil {
+		return err
+	}
+	for _, user := range users {
+		err = write(archive.TypeUser, archive.User{
+			ID:        user.ID,
+			CreatedAt: user.CreatedAt,
+			UpdatedAt: user.UpdatedAt,
+			Name:      user.Name,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	feeds, err := db.DumpFeeds(ctx)
+	if err != nil {
+		return err
+	}
+	for _, feed := range feeds {
+		err = write(archive.TypeFeed, archive.Feed{
+			ID:             feed.ID,
+			CreatedAt:      feed.CreatedAt,
+			UpdatedAt:      feed.UpdatedAt,
+			Name:           feed.Name,
+			URL:            feed.Url,
+			UserID:         feed.UserID,
+			LastFetchedAt:  timePtr(feed.LastFetchedAt),
+			HubURL:         feed.HubUrl.String,
+			HubTopic:       feed.HubTopic.String,
+			Language:       feed.Language.String,
+			RetentionDays:  int32Ptr(feed.RetentionDays),
+			RetentionPosts: int32Ptr(feed.RetentionPosts),
+			SiteURL:        feed.SiteUrl.String,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	follows, err := db.DumpFeedFollows(ctx)
+	if err != nil {
+		return err
+	}
+	for _, follow := range follows {
+		err = write(archive.TypeFollow, archive.Follow{
+			ID:        follow.ID,
+			CreatedAt: follow.CreatedAt,
+			UpdatedAt: follow.UpdatedAt,
+			UserID:    follow.UserID,
+			FeedID:    follow.FeedID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	folders, err := db.DumpFolders(ctx)
+	if err != nil {
+		return err
+	}
+	for _, folder := range folders {
+		err = write(archive.TypeFolder, archive.Folder{
+			ID:        folder.ID,
+			CreatedAt: folder.CreatedAt,
+			UpdatedAt: folder.UpdatedAt,
+			UserID:    folder.UserID,
+			Name:      folder.Name,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	folderFeeds, err := db.DumpFolderFeeds(ctx)
+	if err != nil {
+		return err
+	}
+	for _, folderFeed := range folderFeeds {
+		err = write(archive.TypeFolderFeed, archive.FolderFeed{
+			FolderID:  folderFeed.FolderID,
+			FollowID:  folderFeed.FeedFollowID,
+			CreatedAt: folderFeed.CreatedAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	posts, err := db.DumpPosts(ctx)
+	if err != nil {
+		return err
+	}
+	for _, post := range posts {
+		err = write(archive.TypePost, archive.Post{
+			ID:          post.ID,
+			CreatedAt:   post.CreatedAt,
+			UpdatedAt:   post.UpdatedAt,
+			Title:       post.Title,
+			URL:         post.Url,
+			Description: post.Description,
+			Content:     post.Content,
+			PublishedAt: post.PublishedAt,
+			FeedID:      post.FeedID,
+			Author:      post.Author,
+			Categories:  post.Categories,
+			GUID:        post.Guid,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	postStates, err := db.DumpPostStates(ctx)
+	if err != nil {
+		return err
+	}
+	for _, postState := range postStates {
+		err = write(archive.TypePostState, archive.PostState{
+			UserID:    postState.UserID,
+			PostID:    postState.PostID,
+			CreatedAt: postState.CreatedAt,
+			UpdatedAt: postState.UpdatedAt,
+			Read:      postState.Read,
+			ReadAt:    timePtr(postState.ReadAt),
+			Starred:   postState.Starred,
+			StarredAt: timePtr(postState.StarredAt),
+			Hidden:    postState.Hidden,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	queue, err := db.DumpReadLater(ctx)
+	if err != nil {
+		return err
+	}
+	for _, queued := range queue {
+		err = write(archive.TypeReadLater, archive.ReadLater{
+			UserID:    queued.UserID,
+			PostID:    queued.PostID,
+			CreatedAt: queued.CreatedAt,
+			Position:  queued.Position,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	searches, err := db.DumpSavedSearches(ctx)
+	if err != nil {
+		return err
+	}
+	for _, search := range searches {
+		err = write(archive.TypeSavedSearch, archive.SavedSearch{
+			ID:        search.ID,
+			CreatedAt: search.CreatedAt,
+			UpdatedAt: search.UpdatedAt,
+			UserID:    search.UserID,
+			Name:      search.Name,
+			Query:     search.Query,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	rules, err := db.DumpRules(ctx)
+	if err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		record := archive.Rule{
+			ID:                 rule.ID,
+			CreatedAt:          rule.CreatedAt,
+			UpdatedAt:          rule.UpdatedAt,
+			UserID:             rule.UserID,
+			Action:             rule.Action,
+			TitlePattern:       stringPtr(rule.TitlePattern),
+			DescriptionPattern: stringPtr(rule.DescriptionPattern),
+			Author:             stringPtr(rule.Author),
+			Category:           stringPtr(rule.Category),
+		}
+		if rule.FeedID.Valid {
+			record.FeedID = &rule.FeedID.UUID
+		}
+		err = write(archive.TypeRule, record)
+		if err != nil {
+			return err
+		}
+	}
+
+	tombstones, err := db.DumpPostTombstones(ctx)
+	if err != nil {
+		return err
+	}
+	for _, tombstone := range tombstones {
+		err = write(archive.TypeTombstone, archive.Tombstone{
+			FeedID:   tombstone.FeedID,
+			GUID:     tombstone.Guid,
+			PrunedAt: tombstone.PrunedAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	err = writer.Flush()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Archived %d users, %d feeds, %d follows, %d folders, %d posts, %d post states, %d queued posts, %d saved searches, %d rules\n",
+		counts[archive.TypeUser], counts[archive.TypeFeed], counts[archive.TypeFollow], counts[archive.TypeFolder], counts[archive.TypePost],
+		counts[archive.TypePostState], counts[archive.TypeReadLater], counts[archive.TypeSavedSearch], counts[archive.TypeRule])
+	return nil
+}
+
+// restoreStats counts, per record type, what a restore did with each record.
+// Merged records matched an existing row and were folded into it; kept
+// records lost to the row already in the database.
+type restoreStats struct {
+	restored map[string]int
+	merged   map[string]int
+	kept     map[string]int
+	skipped  map[string]int
+}
+
+// idMap follows archived ids to the ids of the rows they were restored as,
+// which differ whenever a record was merged into an existing one.
+type idMap map[uuid.UUID]uuid.UUID
+
+func (m idMap) lookup(kind string, id uuid.UUID) (uuid.UUID, error) {
+	restoredID, ok := m[id]
+	if !ok {
+		return uuid.Nil, fmt.Errorf("Archive refers to an unknown %s [%s]", kind, id)
+	}
+	return restoredID, nil
+}
+
+// importArchive restores an archive in a single transaction, so a broken
+// archive leaves the database as it was. Rows that already exist are matched
+// on their natural keys (user names, feed and post URLs, folder names) rather
+// than ids, which lets an archive be merged into a database that was never
+// restored from it. Per-user state already in the database wins unless
+// replace is set.
+func importArchive(ctx context.Context, s *state, r io.Reader, replace bool) (restoreStats, error) {
+	stats := restoreStats{
+		restored: make(map[string]int),
+		merged:   make(map[string]int),
+		kept:     make(map[string]int),
+		skipped:  make(map[string]int),
+	}
+	reader, err := archive.NewReader(r)
+	if err != nil {
+		return stats, err
+	}
+
+	tx, err := s.sqlDB.BeginTx(ctx, nil)
+	if err != nil {
+		return stats, err
+	}
+	defer tx.Rollback()
+	qtx := s.db.WithTx(tx)
+
+	users, feeds, follows, folders, posts := idMap{}, idMap{}, idMap{}, idMap{}, idMap{}
+	// a row restored under another id was merged into one already there
+	restored := func(recordType string, archivedID, restoredID uuid.UUID) {
+		if archivedID == restoredID {
+			stats.restored[recordType]++
+		} else {
+			stats.merged[recordType]++
+		}
+	}
+	changed := func(recordType string, rows int64) {
+		if rows > 0 {
+			stats.restored[recordType]++
+		} else {
+			stats.kept[recordType]++
+		}
+	}
+
+	for {
+		record, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return stats, err
+		}
+
+		switch record.Type {
+		case archive.TypeUser:
+			user := archive.User{}
+			if err := reader.Decode(record, &user); err != nil {
+				return stats, err
+			}
+			id, err := qtx.RestoreUser(ctx, database.RestoreUserParams{
+				ID:        user.ID,
+				CreatedAt: user.CreatedAt,
+				UpdatedAt: user.UpdatedAt,
+				Name:      user.Name,
+			})
+			if err != nil {
+				return stats, fmt.Errorf("Error restoring user [%s]: %v", user.Name, err)
+			}
+			users[user.ID] = id
+			restored(record.Type, user.ID, id)
+
+		case archive.TypeFeed:
+			feed := archive.Feed{}
+			if err := reader.Decode(record, &feed); err != nil {
+				return stats, err
+			}
+			userID, err := users.lookup("user", feed.UserID)
+			if err != nil {
+				return stats, err
+			}
+			id, err := qtx.RestoreFeed(ctx, database.RestoreFeedParams{
+				ID:             feed.ID,
+				CreatedAt:      feed.CreatedAt,
+				UpdatedAt:      feed.UpdatedAt,
+				Name:           feed.Name,
+				Url:            feed.URL,
+				UserID:         userID,
+				LastFetchedAt:  nullTime(feed.LastFetchedAt),
+				HubUrl:         nullString(feed.HubURL),
+				HubTopic:       nullString(feed.HubTopic),
+				Language:       nullString(feed.Language),
+				RetentionDays:  nullInt32(feed.RetentionDays),
+				RetentionPosts: nullInt32(feed.RetentionPosts),
+				SiteUrl:        nullString(feed.SiteURL),
+			})
+			if err != nil {
+				return stats, fmt.Errorf("Error restoring feed [%s]: %v", feed.URL, err)
+			}
+			feeds[feed.ID] = id
+			restored(record.Type, feed.ID, id)
+
+		case archive.TypeFollow:
+			follow := archive.Follow{}
+			if err := reader.Decode(record, &follow); err != nil {
+				return stats, err
+			}
+			userID, err := users.lookup("user", follow.UserID)
+			if err != nil {
+				return stats, err
+			}
+			feedID, err := feeds.lookup("feed", follow.FeedID)
+			if err != nil {
+				return stats, err
+			}
+			id, err := qtx.RestoreFeedFollow(ctx, database.RestoreFeedFollowParams{
+				ID:        follow.ID,
+				CreatedAt: follow.CreatedAt,
+				UpdatedAt: follow.UpdatedAt,
+				UserID:    userID,
+				FeedID:    feedID,
+			})
+			if err != nil {
+				return stats, err
+			}
+			follows[follow.ID] = id
+			restored(record.Type, follow.ID, id)
+
+		case archive.TypeFolder:
+			folder := archive.Folder{}
+			if err := reader.Decode(record, &folder); err != nil {
+				return stats, err
+			}
+			userID, err := users.lookup("user", folder.UserID)
+			if err != nil {
+				return stats, err
+			}
+			id, err := qtx.RestoreFolder(ctx, database.RestoreFolderParams{
+				ID:        folder.ID,
+				CreatedAt: folder.CreatedAt,
+				UpdatedAt: folder.UpdatedAt,
+				UserID:    userID,
+				Name:      folder.Name,
+			})
+			if err != nil {
+				return stats, err
+			}
+			folders[folder.ID] = id
+			restored(record.Type, folder.ID, id)
+
+		case archive.TypeFolderFeed:
+			folderFeed := archive.FolderFeed{}
+			if err := reader.Decode(record, &folderFeed); err != nil {
+				return stats, err
+			}
+			folderID, err := folders.lookup("folder", folderFeed.FolderID)
+			if err != nil {
+				return stats, err
+			}
+			followID, err := follows.lookup("follow", folderFeed.FollowID)
+			if err != nil {
+				return stats, err
+			}
+			err = qtx.RestoreFolderFeed(ctx, database.RestoreFolderFeedParams{
+				FolderID:     folderID,
+				FeedFollowID: followID,
+				CreatedAt:    folderFeed.CreatedAt,
+			})
+			if err != nil {
+				return stats, err
+			}
+			stats.restored[record.Type]++
+
+		case archive.TypePost:
+			post := archive.Post{}
+			if err := reader.Decode(record, &post); err != nil {
+				return stats, err
+			}
+			feedID, err := feeds.lookup("feed", post.FeedID)
+			if err != nil {
+				return stats, err
+			}
+			if post.Categories == nil {
+				post.Categories = []string{}
+			}
+			id, err := qtx.RestorePost(ctx, database.RestorePostParams{
+				ID:          post.ID,
+				CreatedAt:   post.CreatedAt,
+				UpdatedAt:   post.UpdatedAt,
+				Title:       post.Title,
+				Url:         post.URL,
+				Description: post.Description,
+				PublishedAt: post.PublishedAt,
+				FeedID:      feedID,
+				Author:      post.Author,
+				Categories:  post.Categories,
+				Content:     post.Content,
+				Guid:        post.GUID,
+			})
+			if err != nil {
+				return stats, fmt.Errorf("Error restoring post [%s]: %v", post.URL, err)
+			}
+			posts[post.ID] = id
+			restored(record.Type, post.ID, id)
+
+		case archive.TypePostState:
+			postState := archive.PostState{}
+			if err := reader.Decode(record, &postState); err != nil {
+				return stats, err
+			}
+			userID, err := users.lookup("user", postState.UserID)
+			if err != nil {
+				return stats, err
+			}
+			postID, err := posts.lookup("post", postState.PostID)
+			if err != nil {
+				return stats, err
+			}
+			rows, err := qtx.RestorePostState(ctx, database.RestorePostStateParams{
+				UserID:    userID,
+				PostID:    postID,
+				CreatedAt: postState.CreatedAt,
+				UpdatedAt: postState.UpdatedAt,
+				Read:      postState.Read,
+				ReadAt:    nullTime(postState.ReadAt),
+				Starred:   postState.Starred,
+				StarredAt: nullTime(postState.StarredAt),
+				Hidden:    postState.Hidden,
+				Replace:   replace,
+			})
+			if err != nil {
+				return stats, err
+			}
+			changed(record.Type, rows)
+
+		case archive.TypeReadLater:
+			queued := archive.ReadLater{}
+			if err := reader.Decode(record, &queued); err != nil {
+				return stats, err
+			}
+			userID, err := users.lookup("user", queued.UserID)
+			if err != nil {
+				return stats, err
+			}
+			postID, err := posts.lookup("post", queued.PostID)
+			if err != nil {
+				return stats, err
+			}
+			rows, err := qtx.RestoreReadLater(ctx, database.RestoreReadLaterParams{
+				UserID:    userID,
+				PostID:    postID,
+				CreatedAt: queued.CreatedAt,
+				Position:  queued.Position,
+				Replace:   replace,
+			})
+			if err != nil {
+				return stats, err
+			}
+			changed(record.Type, rows)
+
+		case archive.TypeSavedSearch:
+			search := archive.SavedSearch{}
+			if err := reader.Decode(record, &search); err != nil {
+				return stats, err
+			}
+			userID, err := users.lookup("user", search.UserID)
+			if err != nil {
+				return stats, err
+			}
+			rows, err := qtx.RestoreSavedSearch(ctx, database.RestoreSavedSearchParams{
+				ID:        search.ID,
+				CreatedAt: search.CreatedAt,
+				UpdatedAt: search.UpdatedAt,
+				UserID:    userID,
+				Name:      search.Name,
+				Query:     search.Query,
+				Replace:   replace,
+			})
+			if err != nil {
+				return stats, err
+			}
+			changed(record.Type, rows)
+
+		case archive.TypeRule:
+			rule := archive.Rule{}
+			if err := reader.Decode(record, &rule); err != nil {
+				return stats, err
+			}
+			userID, err := users.lookup("user", rule.UserID)
+			if err != nil {
+				return stats, err
+			}
+			ruleParams := database.RestoreRuleParams{
+				ID:                 rule.ID,
+				CreatedAt:          rule.CreatedAt,
+				UpdatedAt:          rule.UpdatedAt,
+				UserID:             userID,
+				Action:             rule.Action,
+				TitlePattern:       nullStringPtr(rule.TitlePattern),
+				DescriptionPattern: nullStringPtr(rule.DescriptionPattern),
+				Author:             nullStringPtr(rule.Author),
+				Category:           nullStringPtr(rule.Category),
+				Replace:            replace,
+			}
+			if rule.FeedID != nil {
+				feedID, err := feeds.lookup("feed", *rule.FeedID)
+				if err != nil {
+					return stats, err
+				}
+				ruleParams.FeedID = uuid.NullUUID{UUID: feedID, Valid: true}
+			}
+			rows, err := qtx.RestoreRule(ctx, ruleParams)
+			if err != nil {
+				return stats, err
+			}
+			changed(record.Type, rows)
+
+		case archive.TypeTombstone:
+			tombstone := archive.Tombstone{}
+			if err := reader.Decode(record, &tombstone); err != nil {
+				return stats, err
+			}
+			feedID, err := feeds.lookup("feed", tombstone.FeedID)
+			if err != nil {
+				return stats, err
+			}
+			err = qtx.RestorePostTombstone(ctx, database.RestorePostTombstoneParams{
+				FeedID:   feedID,
+				Guid:     tombstone.GUID,
+				PrunedAt: tombstone.PrunedAt,
+			})
+			if err != nil {
+				return stats, err
+			}
+			stats.restored[record.Type]++
+
+		default:
+			// written by a newer gator with the same archive version
+			stats.skipped[record.Type]++
+		}
+	}
+
+	err = qtx.RenumberReadLater(ctx)
+	if err != nil {
+		return stats, err
+	}
+	return stats, tx.Commit()
+}
+
+func printRestoreStats(stats restoreStats) {
+	labels := []struct{ recordType, label string }{
+		{archive.TypeUser, "users"},
+		{archive.TypeFeed, "feeds"},
+		{archive.TypeFollow, "follows"},
+		{archive.TypeFolder, "folders"},
+		{archive.TypeFolderFeed, "folder entries"},
+		{archive.TypePost, "posts"},
+		{archive.TypePostState, "post states"},
+		{archive.TypeReadLater, "queued posts"},
+		{archive.TypeSavedSearch, "saved searches"},
+		{archive.TypeRule, "rules"},
+		{archive.TypeTombstone, "pruned post markers"},
+	}
+	for _, l := range labels {
+		restored, merged, kept := stats.restored[l.recordType], stats.merged[l.recordType], stats.kept[l.recordType]
+		if restored+merged+kept == 0 {
+			continue
+		}
+		line := fmt.Sprintf("* %s: %d restored", l.label, restored)
+		if merged > 0 {
+			line += fmt.Sprintf(", %d merged into existing ones", merged)
+		}
+		if kept > 0 {
+			line += fmt.Sprintf(", %d already present and kept (use --replace to overwrite)", kept)
+		}
+		fmt.Println(line)
+	}
+	for recordType, count := range stats.skipped {
+		fmt.Printf("* Skipped %d records of unknown type [%s]\n", count, recordType)
+	}
+}
+
+func timePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+func nullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *t, Valid: true}
+}
+
+func int32Ptr(n sql.NullInt32) *int32 {
+	if !n.Valid {
+		return nil
+	}
+	return &n.Int32
+}
+
+func nullInt32(n *int32) sql.NullInt32 {
+	if n == nil {
+		return sql.NullInt32{}
+	}
+	return sql.NullInt32{Int32: *n, Valid: true}
+}
+
+func stringPtr(s sql.NullString) *string {
+	if !s.Valid {
+		return nil
+	}
+	return &s.String
+}
+
+func nullStringPtr(s *string) sql.NullString {
+	if s == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: *s, Valid: true}
+}
+
+// nullString stores empty strings as NULL, which is how gator itself leaves
+// the optional feed columns it hasn't filled in.
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jdwalkerzhere/gator/internal/database"
+	"github.com/jdwalkerzhere/gator/internal/markup"
+	"github.com/jdwalkerzhere/gator/internal/postquery"
+	"github.com/jdwalkerzhere/gator/internal/term"
+)
+
+// browseOptions are the post selection flags shared by every command that
+// lists posts the way browse does.
+type browseOptions struct {
+	all      *bool
+	unread   *bool
+	feed     *string
+	folder   *string
+	since    *string
+	until    *string
+	starred  *bool
+	author   *string
+	category *string
+	offset   *int
+	cursor   *string
+	query    *string
+	search   *string
+}
+
+// addBrowseFlags registers the selection flags. Commands that leave out read
+// posts by default take --all, the ones that include them take --unread.
+func addBrowseFlags(flags *flag.FlagSet, includeRead bool) *browseOptions {
+	options := &browseOptions{
+		feed:     flags.String("feed", "", "only show posts from the feed with this URL or a name containing this"),
+		folder:   flags.String("folder", "", "only show posts from feeds in this folder"),
+		since:    flags.String("since", "", "only show posts published from a date (YYYY-MM-DD) or age (e.g. 7d) on"),
+		until:    flags.String("until", "", "only show posts published before a date (YYYY-MM-DD) or age (e.g. 7d)"),
+		starred:  flags.Bool("starred", false, "only show starred posts"),
+		author:   flags.String("author", "", "only show posts whose author contains this text"),
+		category: flags.String("category", "", "only show posts in this category"),
+		offset:   flags.Int("offset", 0, "skip this many posts"),
+		cursor:   flags.String("cursor", "", "continue after the cursor printed at the end of the previous page"),
+		query:    flags.String("query", "", "only show posts matching a query such as 'feed:golang -is:read generics'"),
+		search:   flags.String("search", "", "only show posts matching one of your saved searches"),
+	}
+	if includeRead {
+		options.unread = flags.Bool("unread", false, "only show posts not yet marked read")
+	} else {
+		options.all = flags.Bool("all", false, "include posts already marked read")
+	}
+	return options
+}
+
+func (o *browseOptions) filter(ctx context.Context, s *state, user database.User, limit int32) (postquery.Filter, error) {
+	filter := postquery.Filter{
+		UserID:     user.ID,
+		Feed:       *o.feed,
+		UnreadOnly: (o.all != nil && !*o.all) || (o.unread != nil && *o.unread),
+		Starred:    *o.starred,
+		Author:     *o.author,
+		Category:   *o.category,
+		Offset:     int32(*o.offset),
+		Limit:      limit,
+	}
+	if *o.folder != "" {
+		folder, err := findFolder(ctx, s, user, *o.folder)
+		if err != nil {
+			return filter, err
+		}
+		filter.Folder = folder.Name
+	}
+	if *o.since != "" {
+		since, err := postquery.ParseCutoff(*o.since)
+		if err != nil {
+			return filter, err
+		}
+		filter.Since = since
+	}
+	if *o.until != "" {
+		until, err := postquery.ParseCutoff(*o.until)
+		if err != nil {
+			return filter, err
+		}
+		filter.Until = until
+	}
+	queryText := *o.query
+	if *o.search != "" {
+		saved, err := findSavedSearch(ctx, s, user, *o.search)
+		if err != nil {
+			return filter, err
+		}
+		queryText = strings.TrimSpace(saved.Query + " " + queryText)
+	}
+	if queryText != "" {
+		query, err := postquery.Parse(queryText)
+		if err != nil {
+			return filter, err
+		}
+		filter.Query = query
+	}
+	if *o.cursor != "" {
+		cursor, err := postquery.ParseCursor(*o.cursor)
+		if err != nil {
+			return filter, err
+		}
+		filter.After = &cursor
+	}
+	return filter, nil
+}
+
+func handlerBrowse(s *state, cmd command) error {
+	flags := newFlagSet(cmd)
+	options := addBrowseFlags(flags.FlagSet, false)
+	color := flags.String("color", "auto", "style descriptions with ANSI escape codes: auto (on a terminal), always or never")
+	err := flags.Parse(cmd.args)
+	if err != nil {
+		return err
+	}
+	textOptions, err := postTextOptions(*color)
+	if err != nil {
+		return err
+	}
+
+	var limit int32 = 2
+	if flags.NArg() > 0 {
+		parsedLimit, err := strconv.ParseInt(flags.Arg(0), 10, 32)
+		if err != nil {
+			return err
+		}
+		limit = int32(parsedLimit)
+	}
+	user, err := s.db.GetUser(context.Background(), s.cfg.CurrentUser)
+	if err != nil {
+		return err
+	}
+
+	filter, err := options.filter(context.Background(), s, user, limit)
+	if err != nil {
+		return err
+	}
+	posts, err := postquery.List(context.Background(), s.sqlDB, filter)
+	if err != nil {
+		return err
+	}
+	err = printPosts(s, posts, textOptions)
+	if err != nil {
+		return err
+	}
+	if len(posts) > 0 && len(posts) == int(limit) {
+		// keep the hint out of the way of scripts reading the posts
+		hint := os.Stdout
+		if s.output != outputText {
+			hint = os.Stderr
+		}
+		fmt.Fprintf(hint, "More posts: --cursor %s\n", posts[len(posts)-1].Cursor())
+	}
+	return nil
+}
+
+type postRecord struct {
+	ID          uuid.UUID `json:"id"`
+	Title       string    `json:"title"`
+	URL         string    `json:"url"`
+	Feed        string    `json:"feed"`
+	FeedURL     string    `json:"feed_url"`
+	Author      string    `json:"author"`
+	Categories  []string  `json:"categories"`
+	PublishedAt time.Time `json:"published_at"`
+	Read        bool      `json:"read"`
+	Starred     bool      `json:"starred"`
+	Description string    `json:"description"`
+	Cursor      string    `json:"cursor"`
+}
+
+func printPosts(s *state, posts []postquery.Post, textOptions markup.TextOptions) error {
+	records := make([]postRecord, len(posts))
+	for i, post := range posts {
+		records[i] = postRecord{
+			ID:          post.ID,
+			Title:       post.Title,
+			URL:         post.Url,
+			Feed:        post.FeedName,
+			FeedURL:     post.FeedUrl,
+			Author:      post.Author,
+			Categories:  post.Categories,
+			PublishedAt: post.PublishedAt,
+			Read:        post.Read,
+			Starred:     post.Starred,
+			Description: post.Description,
+			Cursor:      post.Cursor().String(),
+		}
+		if records[i].Categories == nil {
+			records[i].Categories = []string{}
+		}
+	}
+	return printRecords(s, records, func() {
+		for i, post := range records {
+			title := post.Title
+			if post.Read {
+				title += " (read)"
+			}
+			if post.Starred {
+				title += " (starred)"
+			}
+			fmt.Printf("%d. %s\n\tID: %s\n\tFeed: %s\n\tPublished: %s\n\tLink: %s\n",
+				i+1, title, post.ID, post.Feed, post.PublishedAt.Format(time.DateTime), post.URL)
+			if description := indentedText(post.Description, post.URL, textOptions); description != "" {
+				fmt.Printf("\tDescription:\n%s\n", description)
+			}
+			fmt.Println()
+		}
+	})
+}
+
+// postTextOptions lays out post HTML for stdout: wrapped to fit a terminal
+// once indented under the post's details, and styled on one unless color
+// or $NO_COLOR says otherwise.
+func postTextOptions(color string) (markup.TextOptions, error) {
+	options := markup.TextOptions{Width: 80}
+	terminal := isTerminal(os.Stdout)
+	if terminal {
+		if width, _, err := term.Size(os.Stdout); err == nil && width > 0 {
+			options.Width = min(width, 100)
+		}
+	}
+	// two tabs of indent
+	options.Width = max(options.Width-16, 40)
+	switch color {
+	case "auto":
+		options.Styled = terminal && os.Getenv("NO_COLOR") == ""
+	case "always":
+		options.Styled = true
+	case "never":
+	default:
+		return options, fmt.Errorf("Unknown color setting [%s], please provide auto, always or never\n", color)
+	}
+	return options, nil
+}
+
+// indentedText renders post HTML as text, indented under the post's details.
+func indentedText(fragment, link string, options markup.TextOptions) string {
+	options.Base = postBase(link)
+	text := markup.Text(fragment, options)
+	if text == "" {
+		return ""
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = "\t\t" + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// postBase is the URL relative links in a post resolve against, if the
+// post's link is absolute.
+func postBase(link string) *url.URL {
+	base, err := url.Parse(link)
+	if err != nil || !base.IsAbs() {
+		return nil
+	}
+	return base
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+type command struct {
+	name string
+	args []string
+	help commandHelp
+	// describe, when set, is given the command's flags in place of parsing
+	// them, so completion can see what the command takes.
+	describe func(*flag.FlagSet)
+}
+
+// commandHelp documents a command for `gator help`.
+type commandHelp struct {
+	group string
+	// args is the synopsis of the positional arguments, after any flags.
+	args     string
+	summary  string
+	examples []string
+	// complete suggests the next positional argument for shell completion.
+	complete completer
+	// hidden commands work but aren't listed.
+	hidden bool
+}
+
+// commandGroups orders the groups of the command list.
+var commandGroups = []string{"Users", "Feeds", "Folders", "Posts", "Search and rules", "Aggregation", "Import and export", "Help"}
+
+type commands struct {
+	commandMap map[string]func(*state, command) error
+	help       map[string]commandHelp
+}
+
+func (c *commands) register(name string, f func(*state, command) error, help commandHelp) {
+	c.commandMap[name] = f
+	c.help[name] = help
+}
+
+func (c *commands) run(s *state, cmd command) error {
+	command, ok := c.commandMap[cmd.name]
+	if !ok {
+		suggestions := c.suggest(cmd.name)
+		if len(suggestions) > 0 {
+			return fmt.Errorf("No command [%s] registered in the CLI, did you mean [%s]?\n", cmd.name, strings.Join(suggestions, "] or ["))
+		}
+		return fmt.Errorf("No command [%s] registered in the CLI, run `gator help` for the list\n", cmd.name)
+	}
+	cmd.help = c.help[cmd.name]
+	err := command(s, cmd)
+	// the flag set already printed the help that was asked for
+	if errors.Is(err, flag.ErrHelp) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// names lists the commands in a group, or every listed one.
+func (c *commands) names(group string) []string {
+	var names []string
+	for name, help := range c.help {
+		if !help.hidden && (group == "" || help.group == group) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// suggest finds the commands a mistyped name was probably meant to be.
+func (c *commands) suggest(name string) []string {
+	return closest(name, c.names(""))
+}
+
+func (c *commands) handlerHelp(s *state, cmd command) error {
+	flags := newFlagSet(cmd)
+	err := flags.Parse(cmd.args)
+	if err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		c.printOverview(os.Stdout)
+		return nil
+	}
+	// asking a command for its help makes it print it without running
+	return c.run(s, command{name: flags.Arg(0), args: []string{"--help"}})
+}
+
+func (c *commands) printOverview(w io.Writer) {
+	fmt.Fprintln(w, "gator follows RSS and Atom feeds from the terminal.")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Usage: gator [--output format] <command> [flags] [arguments]")
+	for _, group := range commandGroups {
+		names := c.names(group)
+		if len(names) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n%s:\n", group)
+		for _, name := range names {
+			fmt.Fprintf(w, "  %-12s %s\n", name, c.help[name].summary)
+		}
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Global flags:")
+	fmt.Fprintln(w, "  -o, --output format   print listings as text (the default), table, json, jsonl, csv or tsv")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run `gator help <command>` for a command's flags and examples.")
+}
+
+// flagSet is a command's flag set. It prints the command's help for -h or
+// --help, and points at it when the flags don't parse.
+type flagSet struct {
+	*flag.FlagSet
+	cmd command
+}
+
+func newFlagSet(cmd command) *flagSet {
+	flags := &flagSet{FlagSet: flag.NewFlagSet(cmd.name, flag.ContinueOnError), cmd: cmd}
+	// errors are returned, and help printed, by Parse
+	flags.SetOutput(io.Discard)
+	flags.Usage = func() {}
+	return flags
+}
+
+func (f *flagSet) Parse(args []string) error {
+	if f.cmd.describe != nil {
+		f.cmd.describe(f.FlagSet)
+		return flag.ErrHelp
+	}
+	err := f.FlagSet.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		f.printHelp(os.Stdout)
+		return err
+	}
+	if err != nil {
+		hint := ""
+		if name, ok := strings.CutPrefix(err.Error(), "flag provided but not defined: -"); ok {
+			var flagNames []string
+			f.VisitAll(func(fl *flag.Flag) {
+				flagNames = append(flagNames, fl.Name)
+			})
+			if suggestions := closest(strings.TrimPrefix(name, "-"), flagNames); len(suggestions) > 0 {
+				hint = fmt.Sprintf(", did you mean --%s?", strings.Join(suggestions, " or --"))
+			}
+		}
+		return fmt.Errorf("%v%s\nRun `gator help %s` for usage\n", err, hint, f.Name())
+	}
+	return nil
+}
+
+func (f *flagSet) printHelp(w io.Writer) {
+	usage := "gator " + f.Name()
+	hasFlags := false
+	f.VisitAll(func(*flag.Flag) {
+		hasFlags = true
+	})
+	if hasFlags {
+		usage += " [flags]"
+	}
+	if f.cmd.help.args != "" {
+		usage += " " + f.cmd.help.args
+	}
+	fmt.Fprintf(w, "Usage: %s\n", usage)
+	if f.cmd.help.summary != "" {
+		fmt.Fprintf(w, "\n%s\n", f.cmd.help.summary)
+	}
+	if hasFlags {
+		fmt.Fprintln(w, "\nFlags:")
+		f.SetOutput(w)
+		f.PrintDefaults()
+		f.SetOutput(io.Discard)
+	}
+	if len(f.cmd.help.examples) > 0 {
+		fmt.Fprintln(w, "\nExamples:")
+		for _, example := range f.cmd.help.examples {
+			fmt.Fprintf(w, "  %s\n", example)
+		}
+	}
+}
+
+// parseNoFlags gives a command without flags of its own a flag set, so it
+// still answers --help and turns away flags it doesn't take.
+func (cmd *command) parseNoFlags() error {
+	flags := newFlagSet(*cmd)
+	err := flags.Parse(cmd.args)
+	if err != nil {
+		return err
+	}
+	cmd.args = flags.Args()
+	return nil
+}
+
+// closest returns the candidates within a couple of typos of name, best
+// first.
+func closest(name string, candidates []string) []string {
+	type match struct {
+		name     string
+		distance int
+	}
+	var matches []match
+	for _, candidate := range candidates {
+		distance := editDistance(name, candidate)
+		if distance <= max(1, len(name)/3) || (len(name) > 2 && strings.HasPrefix(candidate, name)) {
+			matches = append(matches, match{candidate, distance})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].distance < matches[j].distance
+	})
+	var names []string
+	for _, m := range matches[:min(len(matches), 3)] {
+		names = append(names, m.name)
+	}
+	return names
+}
+
+// editDistance counts the insertions, deletions, substitutions and swaps of
+// adjacent letters that turn a into b.
+func editDistance(a, b string) int {
+	rows := make([][]int, len(a)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(b)+1)
+		rows[i][0] = i
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			rows[i][j] = min(rows[i-1][j]+1, rows[i][j-1]+1, rows[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				rows[i][j] = min(rows[i][j], rows[i-2][j-2]+1)
+			}
+		}
+	}
+	return rows[len(a)][len(b)]
+}
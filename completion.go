@@ -0,0 +1,369 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// completion is a candidate for the word being completed, with an optional
+// description that zsh and fish show beside it.
+type completion struct {
+	value       string
+	description string
+}
+
+// completer suggests the next positional argument of a command, given the
+// ones before it.
+type completer func(s *state, args []string) ([]completion, error)
+
+// flagCompleters suggest the values of flags that several commands share.
+var flagCompleters = map[string]completer{
+	"feed":   completeFollowing,
+	"folder": completeFolders,
+	"search": completeSearches,
+}
+
+const bashCompletion = `# bash completion for gator
+_gator() {
+    local cur words cword
+    if declare -F _get_comp_words_by_ref >/dev/null; then
+        # keep feed URLs and --flag=value in one word
+        _get_comp_words_by_ref -n =: cur words cword
+    else
+        cur=${COMP_WORDS[COMP_CWORD]}
+        words=("${COMP_WORDS[@]}")
+        cword=$COMP_CWORD
+    fi
+    local IFS=$'\n'
+    COMPREPLY=($(compgen -W "$(gator __complete "${words[@]:1:cword}" 2>/dev/null | cut -f1)" -- "$cur"))
+    if declare -F __ltrim_colon_completions >/dev/null; then
+        __ltrim_colon_completions "$cur"
+    fi
+}
+complete -o default -F _gator gator
+`
+
+const zshCompletion = `#compdef gator
+# zsh completion for gator
+_gator() {
+    local -a candidates
+    local line value description
+    for line in "${(@f)$(gator __complete "${(@)words[2,CURRENT]}" 2>/dev/null)}"; do
+        [[ -z $line ]] && continue
+        value=${line%%$'\t'*}
+        description=${line#*$'\t'}
+        [[ $description == $line ]] && description=
+        candidates+=("${value//:/\\:}${description:+:$description}")
+    done
+    if (( ${#candidates} )); then
+        _describe -t values gator candidates
+    else
+        _files
+    fi
+}
+if [[ $funcstack[1] == _gator ]]; then
+    _gator "$@"
+else
+    compdef _gator gator
+fi
+`
+
+const fishCompletion = `# fish completion for gator
+function __gator_complete
+    set -l words (commandline -opc)
+    set -l candidates (gator __complete $words[2..-1] (commandline -ct) 2>/dev/null)
+    if test (count $candidates) -eq 0
+        __fish_complete_path (commandline -ct)
+    else
+        printf '%s\n' $candidates
+    end
+end
+complete -c gator -f -a '(__gator_complete)'
+`
+
+var completionScripts = map[string]string{
+	"bash": bashCompletion,
+	"zsh":  zshCompletion,
+	"fish": fishCompletion,
+}
+
+func handlerCompletion(s *state, cmd command) error {
+	if err := cmd.parseNoFlags(); err != nil {
+		return err
+	}
+	if len(cmd.args) < 1 {
+		return fmt.Errorf("No shell provided, please provide one of bash, zsh or fish\n")
+	}
+	script, ok := completionScripts[cmd.args[0]]
+	if !ok {
+		return fmt.Errorf("No completion for shell [%s], please provide one of bash, zsh or fish\n", cmd.args[0])
+	}
+	fmt.Print(script)
+	return nil
+}
+
+// handlerComplete is what the completion scripts call, with the words of the
+// command line after gator, the last being the one to complete. It prints a
+// candidate per line, tab separated from its description. Printing nothing
+// leaves the shell to complete file names.
+func (c *commands) handlerComplete(s *state, cmd command) error {
+	if len(cmd.args) == 0 {
+		return nil
+	}
+	// completing is best effort, so a database that can't be reached just
+	// means fewer suggestions
+	candidates, _ := c.complete(s, cmd.args[:len(cmd.args)-1], cmd.args[len(cmd.args)-1])
+	for _, candidate := range candidates {
+		if candidate.description == "" {
+			fmt.Println(candidate.value)
+			continue
+		}
+		fmt.Printf("%s\t%s\n", candidate.value, strings.ReplaceAll(candidate.description, "\n", " "))
+	}
+	return nil
+}
+
+func (c *commands) complete(s *state, words []string, current string) ([]completion, error) {
+	// the global flags come first
+	i := 0
+	for i < len(words) && strings.HasPrefix(words[i], "-") {
+		if isOutputFlag(words[i]) {
+			i++
+		}
+		i++
+	}
+	if i > len(words) {
+		return matching(outputCompletions(), current), nil
+	}
+	if i == len(words) {
+		if flagName, value, ok := strings.Cut(current, "="); ok && isOutputFlag(flagName) {
+			return matching(prefixed(outputCompletions(), current[:len(current)-len(value)]), current), nil
+		}
+		if strings.HasPrefix(current, "-") {
+			return matching([]completion{{value: "--output", description: "print listings as text, table, json, jsonl, csv or tsv"}}, current), nil
+		}
+		candidates, err := c.completeCommands(s, nil)
+		return matching(candidates, current), err
+	}
+
+	name := words[i]
+	handler, ok := c.commandMap[name]
+	if !ok {
+		return nil, nil
+	}
+	help := c.help[name]
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	handler(s, command{name: name, help: help, describe: func(described *flag.FlagSet) {
+		flags = described
+	}})
+
+	// follow the flag package: flags stop at the first positional argument
+	// or at --
+	var args []string
+	parsingFlags := true
+	for j := i + 1; j < len(words); j++ {
+		word := words[j]
+		if !parsingFlags || word == "-" || !strings.HasPrefix(word, "-") {
+			parsingFlags = false
+			args = append(args, word)
+			continue
+		}
+		if word == "--" {
+			parsingFlags = false
+			continue
+		}
+		flagName, _, hasValue := strings.Cut(strings.TrimLeft(word, "-"), "=")
+		if hasValue || isBoolFlag(flags.Lookup(flagName)) {
+			continue
+		}
+		if j == len(words)-1 {
+			// the word being completed is this flag's value
+			return completeFlagValue(s, flagName, "", current)
+		}
+		j++
+	}
+
+	if parsingFlags && strings.HasPrefix(current, "-") {
+		if flagName, value, ok := strings.Cut(strings.TrimLeft(current, "-"), "="); ok {
+			prefix := current[:len(current)-len(value)]
+			return completeFlagValue(s, flagName, prefix, current)
+		}
+		var candidates []completion
+		flags.VisitAll(func(fl *flag.Flag) {
+			candidates = append(candidates, completion{value: "--" + fl.Name, description: fl.Usage})
+		})
+		return matching(candidates, current), nil
+	}
+	if help.complete == nil {
+		return nil, nil
+	}
+	candidates, err := help.complete(s, args)
+	return matching(candidates, current), err
+}
+
+func completeFlagValue(s *state, flagName, prefix, current string) ([]completion, error) {
+	complete, ok := flagCompleters[flagName]
+	if !ok {
+		return nil, nil
+	}
+	candidates, err := complete(s, nil)
+	return matching(prefixed(candidates, prefix), current), err
+}
+
+func isOutputFlag(word string) bool {
+	switch word {
+	case "-o", "--o", "-output", "--output":
+		return true
+	}
+	return false
+}
+
+func isBoolFlag(fl *flag.Flag) bool {
+	if fl == nil {
+		return false
+	}
+	boolFlag, ok := fl.Value.(interface{ IsBoolFlag() bool })
+	return ok && boolFlag.IsBoolFlag()
+}
+
+func outputCompletions() []completion {
+	candidates := make([]completion, len(outputFormats))
+	for i, format := range outputFormats {
+		candidates[i] = completion{value: string(format)}
+	}
+	return candidates
+}
+
+func prefixed(candidates []completion, prefix string) []completion {
+	for i := range candidates {
+		candidates[i].value = prefix + candidates[i].value
+	}
+	return candidates
+}
+
+func matching(candidates []completion, current string) []completion {
+	var matches []completion
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate.value, current) {
+			matches = append(matches, candidate)
+		}
+	}
+	return matches
+}
+
+// byPosition completes each positional argument with its own completer, the
+// last one carrying on for any further arguments. A nil completer suggests
+// nothing.
+func byPosition(completers ...completer) completer {
+	return func(s *state, args []string) ([]completion, error) {
+		complete := completers[min(len(args), len(completers)-1)]
+		if complete == nil {
+			return nil, nil
+		}
+		return complete(s, args)
+	}
+}
+
+// fixed completes with a fixed list of words.
+func fixed(values ...string) completer {
+	return func(_ *state, _ []string) ([]completion, error) {
+		candidates := make([]completion, len(values))
+		for i, value := range values {
+			candidates[i] = completion{value: value}
+		}
+		return candidates, nil
+	}
+}
+
+func (c *commands) completeCommands(_ *state, _ []string) ([]completion, error) {
+	var candidates []completion
+	for _, name := range c.names("") {
+		candidates = append(candidates, completion{value: name, description: c.help[name].summary})
+	}
+	return candidates, nil
+}
+
+func completeUsers(s *state, _ []string) ([]completion, error) {
+	users, err := s.db.GetUsers(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	candidates := make([]completion, len(users))
+	for i, user := range users {
+		candidates[i] = completion{value: user.Name}
+		if user.Name == s.cfg.CurrentUser {
+			candidates[i].description = "current user"
+		}
+	}
+	return candidates, nil
+}
+
+// completeFeeds suggests the URL of every feed, named after the feed.
+func completeFeeds(s *state, _ []string) ([]completion, error) {
+	feeds, err := s.db.GetFeeds(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	candidates := make([]completion, len(feeds))
+	for i, feed := range feeds {
+		candidates[i] = completion{value: feed.Url, description: feed.FeedName}
+	}
+	return candidates, nil
+}
+
+// completeFollowing suggests the URL of every feed the current user follows.
+func completeFollowing(s *state, _ []string) ([]completion, error) {
+	user, err := s.db.GetUser(context.Background(), s.cfg.CurrentUser)
+	if err != nil {
+		return nil, err
+	}
+	following, err := s.db.GetFollowingByFolder(context.Background(), user.ID)
+	if err != nil {
+		return nil, err
+	}
+	// a feed in several folders is listed once for each
+	seen := make(map[string]bool)
+	var candidates []completion
+	for _, feed := range following {
+		if seen[feed.Url] {
+			continue
+		}
+		seen[feed.Url] = true
+		candidates = append(candidates, completion{value: feed.Url, description: feed.FeedName})
+	}
+	return candidates, nil
+}
+
+func completeFolders(s *state, _ []string) ([]completion, error) {
+	user, err := s.db.GetUser(context.Background(), s.cfg.CurrentUser)
+	if err != nil {
+		return nil, err
+	}
+	folders, err := s.db.GetFoldersForUser(context.Background(), user.ID)
+	if err != nil {
+		return nil, err
+	}
+	candidates := make([]completion, len(folders))
+	for i, folder := range folders {
+		candidates[i] = completion{value: folder.Name, description: fmt.Sprintf("%d feeds", folder.FeedCount)}
+	}
+	return candidates, nil
+}
+
+func completeSearches(s *state, _ []string) ([]completion, error) {
+	user, err := s.db.GetUser(context.Background(), s.cfg.CurrentUser)
+	if err != nil {
+		return nil, err
+	}
+	searches, err := s.db.GetSavedSearchesForUser(context.Background(), user.ID)
+	if err != nil {
+		return nil, err
+	}
+	candidates := make([]completion, len(searches))
+	for i, search := range searches {
+		candidates[i] = completion{value: search.Name, description: search.Query}
+	}
+	return candidates, nil
+}
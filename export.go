@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jdwalkerzhere/gator/internal/bundle"
+	"github.com/jdwalkerzhere/gator/internal/opml"
+	"github.com/jdwalkerzhere/gator/internal/postquery"
+)
+
+func handlerExport(s *state, cmd command) error {
+	flags := newFlagSet(cmd)
+	asOPML := flags.Bool("opml", false, "export the feeds you follow as OPML 2.0")
+	asArchive := flags.Bool("archive", false, "back up every user, feed, post and per-user state as a gator archive")
+	asMarkdown := flags.Bool("markdown", false, "bundle the selected posts into a Markdown document")
+	asHTML := flags.Bool("html", false, "bundle the selected posts into a standalone HTML page")
+	asEPUB := flags.Bool("epub", false, "bundle the selected posts into an EPUB book")
+	outPath := flags.String("out", "", "write to this file instead of stdout")
+	options := addBrowseFlags(flags.FlagSet, false)
+	limit := flags.Int("limit", 50, "bundle at most this many posts")
+	title := flags.String("title", "", "title the bundle instead of naming it after you and the date")
+	err := flags.Parse(cmd.args)
+	if err != nil {
+		return err
+	}
+
+	formats := 0
+	for _, selected := range []bool{*asOPML, *asArchive, *asMarkdown, *asHTML, *asEPUB} {
+		if selected {
+			formats++
+		}
+	}
+	if formats != 1 {
+		return fmt.Errorf("Exactly one export format is needed, please provide one of --opml, --archive, --markdown, --html or --epub\n")
+	}
+
+	var book bundle.Book
+	var writeBook func(io.Writer, bundle.Book) error
+	switch {
+	case *asMarkdown:
+		writeBook = bundle.WriteMarkdown
+	case *asHTML:
+		writeBook = bundle.WriteHTML
+	case *asEPUB:
+		writeBook = bundle.WriteEPUB
+	}
+	if writeBook != nil {
+		// collect the posts before creating the file, so a bad selection
+		// doesn't leave an empty one behind
+		book, err = selectBook(context.Background(), s, options, int32(*limit), *title)
+		if err != nil {
+			return err
+		}
+	}
+
+	out, err := createOutput(*outPath)
+	if err != nil {
+		return err
+	}
+	switch {
+	case *asArchive:
+		err = exportArchive(context.Background(), s, out)
+	case *asOPML:
+		err = exportOPML(context.Background(), s, out)
+	default:
+		err = writeBook(out, book)
+	}
+	closeErr := out.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
+
+func exportOPML(ctx context.Context, s *state, w io.Writer) error {
+	user, err := s.db.GetUser(ctx, s.cfg.CurrentUser)
+	if err != nil {
+		return err
+	}
+	follows, err := s.db.GetFollowingByFolder(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	doc := &opml.Document{
+		Version: opml.Version,
+		Head: opml.Head{
+			Title:       fmt.Sprintf("%s's feeds in gator", user.Name),
+			DateCreated: time.Now().Format(time.RFC1123Z),
+		},
+	}
+	for _, follow := range follows {
+		feed := opml.Feed{
+			Title:   follow.FeedName,
+			XMLURL:  follow.Url,
+			HTMLURL: follow.SiteUrl.String,
+		}
+		if follow.FolderName.Valid {
+			feed.Folders = strings.Split(follow.FolderName.String, folderSeparator)
+		}
+		doc.Add(feed)
+	}
+	return doc.Write(w)
+}
+
+// selectBook gathers the posts picked by the browse flags into a book,
+// oldest first within each feed.
+func selectBook(ctx context.Context, s *state, options *browseOptions, limit int32, title string) (bundle.Book, error) {
+	user, err := s.db.GetUser(ctx, s.cfg.CurrentUser)
+	if err != nil {
+		return bundle.Book{}, err
+	}
+	filter, err := options.filter(ctx, s, user, limit)
+	if err != nil {
+		return bundle.Book{}, err
+	}
+	posts, err := postquery.List(ctx, s.sqlDB, filter)
+	if err != nil {
+		return bundle.Book{}, err
+	}
+	if len(posts) == 0 {
+		return bundle.Book{}, fmt.Errorf("No posts match, nothing to export\n")
+	}
+
+	timeNow := time.Now()
+	book := bundle.Book{
+		ID:       "urn:uuid:" + uuid.New().String(),
+		Title:    title,
+		Author:   user.Name,
+		Language: bookLanguage(posts),
+		Created:  timeNow,
+	}
+	if book.Title == "" {
+		book.Title = fmt.Sprintf("%s's reading from gator, %s", user.Name, timeNow.Format("January 2, 2006"))
+	}
+	for i := len(posts) - 1; i >= 0; i-- {
+		post := posts[i]
+		content := post.Content
+		if content == "" {
+			content = post.Description
+		}
+		book.Add(post.FeedName, post.FeedSiteUrl, bundle.Post{
+			Title:     post.Title,
+			Link:      post.Url,
+			Author:    post.Author,
+			Published: post.PublishedAt,
+			HTML:      content,
+		})
+	}
+	return book, nil
+}
+
+// bookLanguage is the language most of the posts' feeds are in, or "und"
+// (undetermined) when none of them has one.
+func bookLanguage(posts []postquery.Post) string {
+	counts := map[string]int{}
+	language := "und"
+	for _, post := range posts {
+		code := languageCode(post.FeedLanguage)
+		if code == "" {
+			continue
+		}
+		counts[code]++
+		if counts[code] > counts[language] {
+			language = code
+		}
+	}
+	return language
+}
+
+// createOutput opens the file an export is written to, or stdout when no
+// path is given.
+func createOutput(path string) (io.WriteCloser, error) {
+	if path == "" || path == "-" {
+		return nopCloser{os.Stdout}, nil
+	}
+	return os.Create(path)
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
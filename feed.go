@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// atomFeed is an Atom document, read into an RSSFeed by parseFeed.
+type atomFeed struct {
+	Title    string      `xml:"title"`
+	Language string      `xml:"http://www.w3.org/XML/1998/namespace lang,attr"`
+	Links    []AtomLink  `xml:"link"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	Title      string     `xml:"title"`
+	Links      []AtomLink `xml:"link"`
+	ID         string     `xml:"id"`
+	Published  string     `xml:"published"`
+	Updated    string     `xml:"updated"`
+	Summary    atomText   `xml:"summary"`
+	Content    atomText   `xml:"content"`
+	Authors    []string   `xml:"author>name"`
+	Categories []struct {
+		Term string `xml:"term,attr"`
+	} `xml:"category"`
+}
+
+// atomText is text, escaped HTML or inline XHTML depending on its type.
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Text  string `xml:",chardata"`
+	Inner string `xml:",innerxml"`
+}
+
+func (t atomText) html() string {
+	if t.Type == "xhtml" {
+		return strings.TrimSpace(t.Inner)
+	}
+	return t.Text
+}
+
+// alternate returns the link to the page a feed or entry stands for.
+func alternate(links []AtomLink) string {
+	for _, link := range links {
+		if link.Rel == "" || link.Rel == "alternate" {
+			return link.Href
+		}
+	}
+	return ""
+}
+
+// parseFeed reads an RSS or Atom document, whichever the root element is.
+func parseFeed(body []byte) (*RSSFeed, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return &RSSFeed{}, fmt.Errorf("Error reading feed: %v", err)
+		}
+		root, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if root.Name.Local != "feed" {
+			rssFeed := RSSFeed{}
+			err = xml.Unmarshal(body, &rssFeed)
+			if err != nil {
+				return &RSSFeed{}, err
+			}
+			return &rssFeed, nil
+		}
+
+		atom := atomFeed{}
+		err = decoder.DecodeElement(&atom, &root)
+		if err != nil {
+			return &RSSFeed{}, err
+		}
+		return atom.rss(), nil
+	}
+}
+
+func (f atomFeed) rss() *RSSFeed {
+	rssFeed := &RSSFeed{}
+	rssFeed.Channel.Title = f.Title
+	rssFeed.Channel.AtomLinks = f.Links
+	rssFeed.Channel.Link = alternate(f.Links)
+	rssFeed.Channel.Language = f.Language
+	for _, entry := range f.Entries {
+		item := RSSItem{
+			Title:       entry.Title,
+			Link:        alternate(entry.Links),
+			Description: entry.Summary.html(),
+			PubDate:     entry.Published,
+			Content:     entry.Content.html(),
+			GUID:        entry.ID,
+		}
+		if item.PubDate == "" {
+			item.PubDate = entry.Updated
+		}
+		if len(entry.Authors) > 0 {
+			item.Author = strings.Join(entry.Authors, ", ")
+		}
+		for _, category := range entry.Categories {
+			item.Categories = append(item.Categories, category.Term)
+		}
+		rssFeed.Channel.Item = append(rssFeed.Channel.Item, item)
+	}
+	return rssFeed
+}
+
+// parsePubDate reads the RFC 1123 dates of RSS and the RFC 3339 ones of Atom.
+func parsePubDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range []string{time.RFC1123Z, time.RFC1123, time.RFC3339} {
+		if pubDate, err := time.Parse(layout, value); err == nil {
+			return pubDate, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("Error parsing datetime %s into RFC1123Z or RFC3339", value)
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jdwalkerzhere/gator/internal/database"
+)
+
+func handlerFolder(s *state, cmd command) error {
+	if err := cmd.parseNoFlags(); err != nil {
+		return err
+	}
+	if len(cmd.args) < 2 {
+		return fmt.Errorf("Insufficient arguments, please provide both folder name and feed url\n")
+	}
+
+	user, err := s.db.GetUser(context.Background(), s.cfg.CurrentUser)
+	if err != nil {
+		return err
+	}
+
+	timeNow := time.Now()
+	folderParams := database.UpsertFolderParams{
+		ID:        uuid.New(),
+		CreatedAt: timeNow,
+		UpdatedAt: timeNow,
+		UserID:    user.ID,
+		Name:      cmd.args[0],
+	}
+	folder, err := s.db.UpsertFolder(context.Background(), folderParams)
+	if err != nil {
+		return err
+	}
+
+	for _, url := range cmd.args[1:] {
+		follow, err := findFeedFollow(context.Background(), s, user, url)
+		if err != nil {
+			return err
+		}
+		addParams := database.AddFollowToFolderParams{
+			FolderID:     folder.ID,
+			FeedFollowID: follow.ID,
+			CreatedAt:    timeNow,
+		}
+		_, err = s.db.AddFollowToFolder(context.Background(), addParams)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Filed [%s] under [%s]\n", url, folder.Name)
+	}
+	return nil
+}
+
+func handlerUnfolder(s *state, cmd command) error {
+	if err := cmd.parseNoFlags(); err != nil {
+		return err
+	}
+	if len(cmd.args) < 2 {
+		return fmt.Errorf("Insufficient arguments, please provide both folder name and feed url\n")
+	}
+
+	user, err := s.db.GetUser(context.Background(), s.cfg.CurrentUser)
+	if err != nil {
+		return err
+	}
+	folder, err := findFolder(context.Background(), s, user, cmd.args[0])
+	if err != nil {
+		return err
+	}
+
+	for _, url := range cmd.args[1:] {
+		follow, err := findFeedFollow(context.Background(), s, user, url)
+		if err != nil {
+			return err
+		}
+		removeParams := database.RemoveFollowFromFolderParams{
+			FolderID:     folder.ID,
+			FeedFollowID: follow.ID,
+		}
+		removed, err := s.db.RemoveFollowFromFolder(context.Background(), removeParams)
+		if err != nil {
+			return err
+		}
+		if removed == 0 {
+			fmt.Printf("[%s] is not in [%s]\n", url, folder.Name)
+		} else {
+			fmt.Printf("Removed [%s] from [%s]\n", url, folder.Name)
+		}
+	}
+	return nil
+}
+
+func handlerFolders(s *state, cmd command) error {
+	if err := cmd.parseNoFlags(); err != nil {
+		return err
+	}
+	user, err := s.db.GetUser(context.Background(), s.cfg.CurrentUser)
+	if err != nil {
+		return err
+	}
+
+	folders, err := s.db.GetFoldersForUser(context.Background(), user.ID)
+	if err != nil {
+		return err
+	}
+	records := make([]folderRecord, len(folders))
+	for i, folder := range folders {
+		records[i] = folderRecord{Name: folder.Name, Feeds: folder.FeedCount}
+	}
+	return printRecords(s, records, func() {
+		for _, folder := range records {
+			fmt.Printf("* %s (%d feeds)\n", folder.Name, folder.Feeds)
+		}
+	})
+}
+
+type folderRecord struct {
+	Name  string `json:"name"`
+	Feeds int64  `json:"feeds"`
+}
+
+func handlerRemoveFolder(s *state, cmd command) error {
+	if err := cmd.parseNoFlags(); err != nil {
+		return err
+	}
+	if len(cmd.args) < 1 {
+		return fmt.Errorf("No folder name provided, please provide one\n")
+	}
+
+	user, err := s.db.GetUser(context.Background(), s.cfg.CurrentUser)
+	if err != nil {
+		return err
+	}
+
+	deleteParams := database.DeleteFolderParams{
+		UserID: user.ID,
+		Name:   cmd.args[0],
+	}
+	deleted, err := s.db.DeleteFolder(context.Background(), deleteParams)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return fmt.Errorf("No folder [%s] exists\n", cmd.args[0])
+	}
+	fmt.Printf("Deleted folder [%s], its feeds are still followed\n", cmd.args[0])
+	return nil
+}
+
+func findFolder(ctx context.Context, s *state, user database.User, name string) (database.Folder, error) {
+	folderParams := database.GetFolderParams{
+		UserID: user.ID,
+		Name:   name,
+	}
+	folder, err := s.db.GetFolder(ctx, folderParams)
+	if errors.Is(err, sql.ErrNoRows) {
+		return folder, fmt.Errorf("No folder [%s] exists\n", name)
+	}
+	return folder, err
+}
+
+func findFeedFollow(ctx context.Context, s *state, user database.User, url string) (database.FeedFollow, error) {
+	feed, err := s.db.GetFeed(ctx, url)
+	if err != nil {
+		return database.FeedFollow{}, err
+	}
+	followParams := database.GetFeedFollowParams{
+		UserID: user.ID,
+		FeedID: feed.ID,
+	}
+	follow, err := s.db.GetFeedFollow(ctx, followParams)
+	if errors.Is(err, sql.ErrNoRows) {
+		return follow, fmt.Errorf("User [%s] does not follow [%s]\n", user.Name, url)
+	}
+	return follow, err
+}
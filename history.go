@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jdwalkerzhere/gator/internal/database"
+)
+
+const fetchLogRetention = 30 * 24 * time.Hour
+
+func logFetch(ctx context.Context, s *state, result scrapeResult, fetchErr error) error {
+	logParams := database.CreateFetchLogParams{
+		ID:         uuid.New(),
+		FeedID:     result.feed.ID,
+		StartedAt:  result.startedAt,
+		FinishedAt: time.Now(),
+		HttpStatus: sql.NullInt32{Int32: int32(result.fetch.status), Valid: result.fetch.status != 0},
+		Bytes:      result.fetch.bytes,
+		ItemsSeen:  int32(result.itemsSeen),
+		NewPosts:   int32(result.newPosts),
+	}
+	if fetchErr != nil {
+		logParams.Error = sql.NullString{String: fetchErr.Error(), Valid: true}
+	}
+	err := s.db.CreateFetchLog(ctx, logParams)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.PruneFetchLog(ctx, time.Now().Add(-fetchLogRetention))
+	return err
+}
+
+func handlerHistory(s *state, cmd command) error {
+	if err := cmd.parseNoFlags(); err != nil {
+		return err
+	}
+	if len(cmd.args) < 1 {
+		return fmt.Errorf("No URL provided, please provide the feed to show history for\n")
+	}
+	var limit int32 = 20
+	if len(cmd.args) > 1 {
+		parsedLimit, err := strconv.ParseInt(cmd.args[1], 10, 32)
+		if err != nil {
+			return err
+		}
+		limit = int32(parsedLimit)
+	}
+
+	feed, err := s.db.GetFeed(context.Background(), cmd.args[0])
+	if err != nil {
+		return err
+	}
+
+	logParams := database.GetFetchLogForFeedParams{
+		FeedID: feed.ID,
+		Limit:  limit,
+	}
+	history, err := s.db.GetFetchLogForFeed(context.Background(), logParams)
+	if err != nil {
+		return err
+	}
+	records := make([]fetchRecord, len(history))
+	for i, entry := range history {
+		records[i] = fetchRecord{
+			StartedAt:  entry.StartedAt,
+			FinishedAt: entry.FinishedAt,
+			Bytes:      entry.Bytes,
+			ItemsSeen:  entry.ItemsSeen,
+			NewPosts:   entry.NewPosts,
+			Error:      stringPtr(entry.Error),
+		}
+		if entry.HttpStatus.Valid {
+			records[i].HTTPStatus = &entry.HttpStatus.Int32
+		}
+	}
+	return printRecords(s, records, func() {
+		if len(records) == 0 {
+			fmt.Printf("No fetches of [%s] recorded in the last %d days\n", feed.Name, int(fetchLogRetention.Hours()/24))
+			return
+		}
+		fmt.Printf("Fetch history for [%s]:\n", feed.Name)
+		for _, entry := range records {
+			httpStatus := "no response"
+			if entry.HTTPStatus != nil {
+				httpStatus = fmt.Sprintf("HTTP %d", *entry.HTTPStatus)
+			}
+			took := entry.FinishedAt.Sub(entry.StartedAt).Round(time.Millisecond)
+			fmt.Printf("* %s (%s) - %s, %d bytes, %d items, %d new\n",
+				entry.StartedAt.Format(time.DateTime), took, httpStatus, entry.Bytes, entry.ItemsSeen, entry.NewPosts)
+			if entry.Error != nil {
+				fmt.Printf("\t- Error: %s\n", *entry.Error)
+			}
+		}
+	})
+}
+
+type fetchRecord struct {
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	HTTPStatus *int32    `json:"http_status"`
+	Bytes      int64     `json:"bytes"`
+	ItemsSeen  int32     `json:"items_seen"`
+	NewPosts   int32     `json:"new_posts"`
+	Error      *string   `json:"error"`
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jdwalkerzhere/gator/internal/database"
+	"github.com/jdwalkerzhere/gator/internal/opml"
+)
+
+// folderSeparator joins nested OPML outlines into a single folder name.
+const folderSeparator = "/"
+
+func handlerImport(s *state, cmd command) error {
+	flags := newFlagSet(cmd)
+	asArchive := flags.Bool("archive", false, "restore a gator archive made with `gator export --archive`")
+	replace := flags.Bool("replace", false, "let the archive's read, starred and queued state and saved searches overwrite what's already here")
+	err := flags.Parse(cmd.args)
+	if err != nil {
+		return err
+	}
+	if flags.NArg() < 1 {
+		return fmt.Errorf("No file provided, please provide the OPML file or archive to import\n")
+	}
+	path := flags.Arg(0)
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if *asArchive {
+		stats, err := importArchive(context.Background(), s, file, *replace)
+		if err != nil {
+			return fmt.Errorf("Error restoring [%s], nothing was changed: %v\n", path, err)
+		}
+		fmt.Printf("Restored [%s]:\n", path)
+		printRestoreStats(stats)
+		return nil
+	}
+	if *replace {
+		return fmt.Errorf("--replace only applies to --archive\n")
+	}
+
+	doc, err := opml.Parse(file)
+	if err != nil {
+		return fmt.Errorf("Error parsing [%s] as OPML: %v\n", path, err)
+	}
+
+	user, err := s.db.GetUser(context.Background(), s.cfg.CurrentUser)
+	if err != nil {
+		return err
+	}
+
+	created, followed, alreadyFollowed, duplicates := 0, 0, 0, 0
+	var failures []string
+	seen := make(map[string]bool)
+	for _, outline := range doc.Feeds() {
+		// a feed listed twice is still filed under both folders
+		if seen[outline.XMLURL] {
+			fmt.Printf("Duplicate: [%s] is listed more than once\n", outline.XMLURL)
+			duplicates++
+		}
+
+		isNew, isFollowed, err := importFeed(context.Background(), s, user, outline)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", outline.XMLURL, err))
+			continue
+		}
+		if isNew {
+			created++
+		}
+		if isFollowed {
+			followed++
+		} else if !seen[outline.XMLURL] {
+			fmt.Printf("Duplicate: already following [%s]\n", outline.XMLURL)
+			alreadyFollowed++
+		}
+		seen[outline.XMLURL] = true
+	}
+
+	fmt.Printf("Imported [%s]: followed %d feeds (%d new to gator), %d already followed, %d duplicates, %d failed\n",
+		path, followed, created, alreadyFollowed, duplicates, len(failures))
+	for _, failure := range failures {
+		fmt.Printf("\t- Failed: %s\n", failure)
+	}
+	return nil
+}
+
+// importFeed creates the feed if gator doesn't know it yet, follows it and
+// files it under the outline's folder. It reports whether the feed was new
+// and whether it was newly followed.
+func importFeed(ctx context.Context, s *state, user database.User, outline opml.Feed) (bool, bool, error) {
+	feedURL, err := url.Parse(outline.XMLURL)
+	if err != nil || (feedURL.Scheme != "http" && feedURL.Scheme != "https") {
+		return false, false, fmt.Errorf("Not an http(s) feed URL")
+	}
+
+	timeNow := time.Now()
+	isNew := false
+	feed, err := s.db.GetFeed(ctx, feedURL.String())
+	if errors.Is(err, sql.ErrNoRows) {
+		name := outline.Title
+		if name == "" {
+			name = outline.XMLURL
+		}
+		feedParams := database.CreateFeedParams{
+			ID:        uuid.New(),
+			CreatedAt: timeNow,
+			UpdatedAt: timeNow,
+			Name:      name,
+			Url:       feedURL.String(),
+			UserID:    user.ID,
+		}
+		feed, err = s.db.CreateFeed(ctx, feedParams)
+		isNew = true
+	}
+	if err != nil {
+		return false, false, err
+	}
+	if outline.HTMLURL != "" && !feed.SiteUrl.Valid {
+		siteParams := database.SetFeedSiteURLParams{
+			ID:      feed.ID,
+			SiteUrl: sql.NullString{String: outline.HTMLURL, Valid: true},
+		}
+		err = s.db.SetFeedSiteURL(ctx, siteParams)
+		if err != nil {
+			return isNew, false, err
+		}
+	}
+
+	isFollowed := false
+	followParams := database.GetFeedFollowParams{
+		UserID: user.ID,
+		FeedID: feed.ID,
+	}
+	follow, err := s.db.GetFeedFollow(ctx, followParams)
+	if errors.Is(err, sql.ErrNoRows) {
+		createParams := database.CreateFeedFollowParams{
+			ID:        uuid.New(),
+			CreatedAt: timeNow,
+			UpdatedAt: timeNow,
+			UserID:    user.ID,
+			FeedID:    feed.ID,
+		}
+		var created database.CreateFeedFollowRow
+		created, err = s.db.CreateFeedFollow(ctx, createParams)
+		follow = database.FeedFollow{ID: created.ID, UserID: user.ID, FeedID: feed.ID}
+		isFollowed = true
+	}
+	if err != nil {
+		return isNew, false, err
+	}
+
+	var folders []string
+	for _, folder := range outline.Folders {
+		if folder != "" {
+			folders = append(folders, folder)
+		}
+	}
+	if len(folders) == 0 {
+		return isNew, isFollowed, nil
+	}
+	folderParams := database.UpsertFolderParams{
+		ID:        uuid.New(),
+		CreatedAt: timeNow,
+		UpdatedAt: timeNow,
+		UserID:    user.ID,
+		Name:      strings.Join(folders, folderSeparator),
+	}
+	folder, err := s.db.UpsertFolder(ctx, folderParams)
+	if err != nil {
+		return isNew, isFollowed, err
+	}
+	addParams := database.AddFollowToFolderParams{
+		FolderID:     folder.ID,
+		FeedFollowID: follow.ID,
+		CreatedAt:    timeNow,
+	}
+	_, err = s.db.AddFollowToFolder(ctx, addParams)
+	return isNew, isFollowed, err
+}
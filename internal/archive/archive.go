@@ -0,0 +1,238 @@
+// Package archive reads and writes gator backups: a header line followed by
+// one JSON record per line. The records only use plain JSON types, so an
+// archive can be restored into any database gator supports.
+package archive
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	Format  = "gator-archive"
+	Version = 1
+)
+
+// Record types, in the order they are written so that everything a record
+// refers to comes before it.
+const (
+	TypeUser        = "user"
+	TypeFeed        = "feed"
+	TypeFollow      = "follow"
+	TypeFolder      = "folder"
+	TypeFolderFeed  = "folder_feed"
+	TypePost        = "post"
+	TypePostState   = "post_state"
+	TypeReadLater   = "read_later"
+	TypeSavedSearch = "saved_search"
+	TypeRule        = "rule"
+	TypeTombstone   = "tombstone"
+)
+
+type Header struct {
+	Format    string    `json:"format"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Record struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+type User struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Name      string    `json:"name"`
+}
+
+type Feed struct {
+	ID             uuid.UUID  `json:"id"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	Name           string     `json:"name"`
+	URL            string     `json:"url"`
+	UserID         uuid.UUID  `json:"user_id"`
+	LastFetchedAt  *time.Time `json:"last_fetched_at,omitempty"`
+	HubURL         string     `json:"hub_url,omitempty"`
+	HubTopic       string     `json:"hub_topic,omitempty"`
+	Language       string     `json:"language,omitempty"`
+	RetentionDays  *int32     `json:"retention_days,omitempty"`
+	RetentionPosts *int32     `json:"retention_posts,omitempty"`
+	SiteURL        string     `json:"site_url,omitempty"`
+}
+
+type Follow struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	UserID    uuid.UUID `json:"user_id"`
+	FeedID    uuid.UUID `json:"feed_id"`
+}
+
+type Folder struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	UserID    uuid.UUID `json:"user_id"`
+	Name      string    `json:"name"`
+}
+
+type FolderFeed struct {
+	FolderID  uuid.UUID `json:"folder_id"`
+	FollowID  uuid.UUID `json:"follow_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Post struct {
+	ID          uuid.UUID `json:"id"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Title       string    `json:"title"`
+	URL         string    `json:"url"`
+	Description string    `json:"description"`
+	Content     string    `json:"content,omitempty"`
+	PublishedAt time.Time `json:"published_at"`
+	FeedID      uuid.UUID `json:"feed_id"`
+	Author      string    `json:"author,omitempty"`
+	Categories  []string  `json:"categories"`
+	GUID        string    `json:"guid,omitempty"`
+}
+
+type PostState struct {
+	UserID    uuid.UUID  `json:"user_id"`
+	PostID    uuid.UUID  `json:"post_id"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	Read      bool       `json:"read"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+	Starred   bool       `json:"starred"`
+	StarredAt *time.Time `json:"starred_at,omitempty"`
+	Hidden    bool       `json:"hidden"`
+}
+
+type ReadLater struct {
+	UserID    uuid.UUID `json:"user_id"`
+	PostID    uuid.UUID `json:"post_id"`
+	CreatedAt time.Time `json:"created_at"`
+	Position  int32     `json:"position"`
+}
+
+type SavedSearch struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	UserID    uuid.UUID `json:"user_id"`
+	Name      string    `json:"name"`
+	Query     string    `json:"query"`
+}
+
+type Rule struct {
+	ID                 uuid.UUID  `json:"id"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+	UserID             uuid.UUID  `json:"user_id"`
+	Action             string     `json:"action"`
+	FeedID             *uuid.UUID `json:"feed_id,omitempty"`
+	TitlePattern       *string    `json:"title_pattern,omitempty"`
+	DescriptionPattern *string    `json:"description_pattern,omitempty"`
+	Author             *string    `json:"author,omitempty"`
+	Category           *string    `json:"category,omitempty"`
+}
+
+type Tombstone struct {
+	FeedID   uuid.UUID `json:"feed_id"`
+	GUID     string    `json:"guid"`
+	PrunedAt time.Time `json:"pruned_at"`
+}
+
+type Writer struct {
+	w       *bufio.Writer
+	encoder *json.Encoder
+}
+
+func NewWriter(w io.Writer) (*Writer, error) {
+	buffered := bufio.NewWriter(w)
+	writer := &Writer{w: buffered, encoder: json.NewEncoder(buffered)}
+	err := writer.encoder.Encode(Header{Format: Format, Version: Version, CreatedAt: time.Now().UTC()})
+	if err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+func (w *Writer) Write(recordType string, data any) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return w.encoder.Encode(Record{Type: recordType, Data: raw})
+}
+
+func (w *Writer) Flush() error {
+	return w.w.Flush()
+}
+
+type Reader struct {
+	Header  Header
+	scanner *bufio.Scanner
+	line    int
+}
+
+func NewReader(r io.Reader) (*Reader, error) {
+	scanner := bufio.NewScanner(r)
+	// posts carry their full content, which easily outgrows the default
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	reader := &Reader{scanner: scanner}
+
+	if !scanner.Scan() {
+		if scanner.Err() != nil {
+			return nil, scanner.Err()
+		}
+		return nil, fmt.Errorf("Archive is empty")
+	}
+	reader.line++
+	err := json.Unmarshal(scanner.Bytes(), &reader.Header)
+	if err != nil || reader.Header.Format != Format {
+		return nil, fmt.Errorf("Not a gator archive")
+	}
+	if reader.Header.Version > Version {
+		return nil, fmt.Errorf("Archive version %d is newer than the %d this gator reads, please upgrade", reader.Header.Version, Version)
+	}
+	return reader, nil
+}
+
+// Next returns the next record, or io.EOF after the last one.
+func (r *Reader) Next() (Record, error) {
+	for r.scanner.Scan() {
+		r.line++
+		if len(r.scanner.Bytes()) == 0 {
+			continue
+		}
+		record := Record{}
+		err := json.Unmarshal(r.scanner.Bytes(), &record)
+		if err != nil {
+			return record, fmt.Errorf("Line %d: %v", r.line, err)
+		}
+		return record, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return Record{}, err
+	}
+	return Record{}, io.EOF
+}
+
+// Decode unmarshals the data of the record the reader just returned.
+func (r *Reader) Decode(record Record, v any) error {
+	err := json.Unmarshal(record.Data, v)
+	if err != nil {
+		return fmt.Errorf("Line %d: invalid %s: %v", r.line, record.Type, err)
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package archive
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestRoundTrip(t *testing.T) {
+	created := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	days := int32(90)
+	user := User{ID: uuid.New(), CreatedAt: created, UpdatedAt: created, Name: "ada"}
+	feed := Feed{
+		ID:            uuid.New(),
+		CreatedAt:     created,
+		UpdatedAt:     created,
+		Name:          "Blog",
+		URL:           "https://blog.example.com/feed.xml",
+		UserID:        user.ID,
+		LastFetchedAt: &created,
+		Language:      "english",
+		RetentionDays: &days,
+	}
+	post := Post{
+		ID:          uuid.New(),
+		CreatedAt:   created,
+		UpdatedAt:   created,
+		Title:       "First",
+		URL:         "https://blog.example.com/1",
+		Description: "line one\nline two",
+		Content:     "<p>content</p>",
+		PublishedAt: created,
+		FeedID:      feed.ID,
+		GUID:        "https://blog.example.com/1",
+		Categories:  []string{"go"},
+	}
+
+	var out bytes.Buffer
+	writer, err := NewWriter(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, record := range []struct {
+		recordType string
+		data       any
+	}{
+		{TypeUser, user},
+		{TypeFeed, feed},
+		{TypePost, post},
+	} {
+		if err := writer.Write(record.recordType, record.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := NewReader(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reader.Header.Format != Format || reader.Header.Version != Version || reader.Header.CreatedAt.IsZero() {
+		t.Errorf("header = %+v", reader.Header)
+	}
+
+	var gotUser User
+	var gotFeed Feed
+	var gotPost Post
+	for _, want := range []struct {
+		recordType string
+		into       any
+	}{
+		{TypeUser, &gotUser},
+		{TypeFeed, &gotFeed},
+		{TypePost, &gotPost},
+	} {
+		record, err := reader.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if record.Type != want.recordType {
+			t.Fatalf("record type = %s, want %s", record.Type, want.recordType)
+		}
+		if err := reader.Decode(record, want.into); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := reader.Next(); !errors.Is(err, io.EOF) {
+		t.Errorf("Next after the last record = %v, want io.EOF", err)
+	}
+
+	if !reflect.DeepEqual(gotUser, user) {
+		t.Errorf("user = %+v, want %+v", gotUser, user)
+	}
+	if !reflect.DeepEqual(gotFeed, feed) {
+		t.Errorf("feed = %+v, want %+v", gotFeed, feed)
+	}
+	if !reflect.DeepEqual(gotPost, post) {
+		t.Errorf("post differs after the round trip")
+	}
+}
+
+func TestNewReaderErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		archive string
+		wantErr string
+	}{
+		{name: "empty", archive: "", wantErr: "Archive is empty"},
+		{name: "not json", archive: "user,feed\n", wantErr: "Not a gator archive"},
+		{name: "other format", archive: `{"format":"other","version":1}` + "\n", wantErr: "Not a gator archive"},
+		{name: "newer version", archive: fmt.Sprintf(`{"format":%q,"version":%d}`, Format, Version+1) + "\n", wantErr: "please upgrade"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewReader(strings.NewReader(tt.archive))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("NewReader error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReaderLineErrors(t *testing.T) {
+	archive := fmt.Sprintf(`{"format":%q,"version":%d}`, Format, Version) + "\n\n" +
+		`{"type":"user","data":{"id":"not a uuid"}}` + "\n" +
+		`{"type":` + "\n"
+	reader, err := NewReader(strings.NewReader(archive))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// blank lines are skipped but still counted
+	record, err := reader.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = reader.Decode(record, &User{})
+	if err == nil || !strings.HasPrefix(err.Error(), "Line 3: invalid user") {
+		t.Errorf("Decode error = %v, want it on line 3", err)
+	}
+
+	_, err = reader.Next()
+	if err == nil || !strings.HasPrefix(err.Error(), "Line 4:") {
+		t.Errorf("Next error = %v, want it on line 4", err)
+	}
+}
+
+func TestReaderLongLines(t *testing.T) {
+	var out bytes.Buffer
+	writer, err := NewWriter(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := strings.Repeat("x", 2*1024*1024)
+	if err := writer.Write(TypePost, Post{Content: content}); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := NewReader(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	record, err := reader.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	post := Post{}
+	if err := reader.Decode(record, &post); err != nil {
+		t.Fatal(err)
+	}
+	if post.Content != content {
+		t.Errorf("content of %d bytes came back as %d", len(content), len(post.Content))
+	}
+	if _, err := reader.Next(); !errors.Is(err, io.EOF) {
+		t.Errorf("Next after the last record = %v, want io.EOF", err)
+	}
+}
@@ -0,0 +1,67 @@
+// Package bundle collects posts into a single document for reading offline:
+// Markdown, a standalone HTML page or an EPUB book, with the posts grouped
+// by the feed they came from.
+package bundle
+
+import (
+	"net/url"
+	"time"
+)
+
+type Book struct {
+	// ID identifies the book to e-readers, which use it to tell editions of
+	// the same book apart.
+	ID       string
+	Title    string
+	Author   string
+	Language string
+	Created  time.Time
+	Sections []Section
+}
+
+// Section holds the posts of one feed.
+type Section struct {
+	Title   string
+	SiteURL string
+	Posts   []Post
+}
+
+type Post struct {
+	Title     string
+	Link      string
+	Author    string
+	Published time.Time
+	// HTML is the post's content as the feed published it.
+	HTML string
+}
+
+// Add files a post under the section of its feed, creating the section the
+// first time the feed comes up.
+func (b *Book) Add(feed, siteURL string, post Post) {
+	for i := range b.Sections {
+		if b.Sections[i].Title == feed {
+			b.Sections[i].Posts = append(b.Sections[i].Posts, post)
+			return
+		}
+	}
+	b.Sections = append(b.Sections, Section{Title: feed, SiteURL: siteURL, Posts: []Post{post}})
+}
+
+// base is the post's own link, which relative links in its content are
+// resolved against.
+func (p Post) base() *url.URL {
+	base, err := url.Parse(p.Link)
+	if err != nil || !base.IsAbs() {
+		return nil
+	}
+	return base
+}
+
+// byline is the line shown under a post's title.
+func (p Post) byline(section Section) string {
+	byline := section.Title + ", " + p.Published.Format("January 2, 2006")
+	if p.Author != "" {
+		byline = p.Author + " in " + byline
+	}
+	return byline
+}
@@ -0,0 +1,206 @@
+package bundle
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"html/template"
+	"io"
+	"time"
+
+	"github.com/jdwalkerzhere/gator/internal/markup"
+)
+
+const containerXML = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`
+
+var packageTemplate = template.Must(template.New("content.opf").Parse(`<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="book-id">{{.ID}}</dc:identifier>
+    <dc:title>{{.Title}}</dc:title>
+    <dc:creator>{{.Author}}</dc:creator>
+    <dc:language>{{.Language}}</dc:language>
+    <dc:date>{{.Created}}</dc:date>
+    <meta property="dcterms:modified">{{.Created}}</meta>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="ncx" href="toc.ncx" media-type="application/x-dtbncx+xml"/>
+    <item id="style" href="style.css" media-type="text/css"/>
+    {{- range .Chapters}}
+    <item id="{{.ID}}" href="{{.ID}}.xhtml" media-type="application/xhtml+xml"/>
+    {{- end}}
+  </manifest>
+  <spine toc="ncx">
+    <itemref idref="nav"/>
+    {{- range .Chapters}}
+    <itemref idref="{{.ID}}"/>
+    {{- end}}
+  </spine>
+</package>
+`))
+
+var navTemplate = template.Must(template.New("nav.xhtml").Parse(`<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" lang="{{.Language}}" xml:lang="{{.Language}}">
+<head>
+<title>{{.Title}}</title>
+<link rel="stylesheet" type="text/css" href="style.css"/>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="byline">Collected by {{.Author}} on {{.Date}}</p>
+<nav epub:type="toc" id="toc">
+<h2>Contents</h2>
+<ol>
+{{- range $chapter := .Chapters}}
+<li><a href="{{.ID}}.xhtml">{{.Title}}</a>
+<ol>
+{{- range .Posts}}
+<li><a href="{{$chapter.ID}}.xhtml#{{.ID}}">{{.Title}}</a></li>
+{{- end}}
+</ol>
+</li>
+{{- end}}
+</ol>
+</nav>
+</body>
+</html>
+`))
+
+var ncxTemplate = template.Must(template.New("toc.ncx").Parse(`<ncx xmlns="http://www.daisy.org/z3986/2005/ncx/" version="2005-1">
+  <head>
+    <meta name="dtb:uid" content="{{.ID}}"/>
+  </head>
+  <docTitle><text>{{.Title}}</text></docTitle>
+  <navMap>
+    {{- range $i, $chapter := .Chapters}}
+    <navPoint id="{{.ID}}" playOrder="{{$.PlayOrder $i -1}}">
+      <navLabel><text>{{.Title}}</text></navLabel>
+      <content src="{{.ID}}.xhtml"/>
+      {{- range $j, $post := .Posts}}
+      <navPoint id="{{.ID}}" playOrder="{{$.PlayOrder $i $j}}">
+        <navLabel><text>{{.Title}}</text></navLabel>
+        <content src="{{$chapter.ID}}.xhtml#{{.ID}}"/>
+      </navPoint>
+      {{- end}}
+    </navPoint>
+    {{- end}}
+  </navMap>
+</ncx>
+`))
+
+var chapterTemplate = template.Must(template.New("chapter.xhtml").Parse(`<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" lang="{{.Language}}" xml:lang="{{.Language}}">
+<head>
+<title>{{.Chapter.Title}}</title>
+<link rel="stylesheet" type="text/css" href="style.css"/>
+</head>
+<body>
+<section id="{{.Chapter.ID}}">
+<h1>{{if .Chapter.SiteURL}}<a href="{{.Chapter.SiteURL}}">{{.Chapter.Title}}</a>{{else}}{{.Chapter.Title}}{{end}}</h1>
+{{- range .Chapter.Posts}}
+<article id="{{.ID}}">
+<h2>{{if .Link}}<a href="{{.Link}}">{{.Title}}</a>{{else}}{{.Title}}{{end}}</h2>
+<p class="byline">{{.Byline}}</p>
+{{.Content}}
+</article>
+{{- end}}
+</section>
+</body>
+</html>
+`))
+
+// epubBook is what the EPUB templates are executed with.
+type epubBook struct {
+	ID       string
+	Title    string
+	Author   string
+	Language string
+	Date     string
+	Created  string
+	Chapters []chapter
+}
+
+// PlayOrder numbers the table of contents entries in reading order, a post
+// of -1 being the chapter itself.
+func (b epubBook) PlayOrder(chapter, post int) int {
+	order := 1
+	for i := 0; i < chapter; i++ {
+		order += 1 + len(b.Chapters[i].Posts)
+	}
+	return order + post + 1
+}
+
+// epubFile is a file in the book, either fixed text or a template to execute.
+type epubFile struct {
+	name     string
+	template *template.Template
+	data     any
+	text     string
+}
+
+// WriteEPUB writes the book as an EPUB 3 file, with a chapter per feed. It
+// also carries an EPUB 2 table of contents for older readers. Images are
+// replaced by their alt text, since the book can't load them.
+func WriteEPUB(w io.Writer, book Book) error {
+	data := epubBook{
+		ID:       book.ID,
+		Title:    book.Title,
+		Author:   book.Author,
+		Language: book.Language,
+		Date:     book.Created.Format("January 2, 2006"),
+		Created:  book.Created.UTC().Format(time.RFC3339),
+		Chapters: chapters(book, markup.SanitizeOptions{NoImages: true}),
+	}
+
+	archive := zip.NewWriter(w)
+	// the mimetype has to come first and uncompressed, so readers can
+	// recognise the file by its first bytes
+	mimetype, err := archive.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(mimetype, "application/epub+zip")
+	if err != nil {
+		return err
+	}
+
+	files := []epubFile{
+		{name: "META-INF/container.xml", text: containerXML},
+		{name: "OEBPS/content.opf", template: packageTemplate, data: data},
+		{name: "OEBPS/nav.xhtml", template: navTemplate, data: data},
+		{name: "OEBPS/toc.ncx", template: ncxTemplate, data: data},
+		{name: "OEBPS/style.css", text: stylesheet},
+	}
+	for _, chapter := range data.Chapters {
+		files = append(files, epubFile{
+			name:     "OEBPS/" + chapter.ID + ".xhtml",
+			template: chapterTemplate,
+			data:     map[string]any{"Language": data.Language, "Chapter": chapter},
+		})
+	}
+
+	for _, file := range files {
+		f, err := archive.Create(file.name)
+		if err != nil {
+			return err
+		}
+		if file.template == nil {
+			_, err = io.WriteString(f, file.text)
+		} else {
+			// html/template would escape the XML declaration in the template
+			_, err = io.WriteString(f, xml.Header)
+			if err == nil {
+				err = file.template.Execute(f, file.data)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
@@ -0,0 +1,117 @@
+package bundle
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+
+	"github.com/jdwalkerzhere/gator/internal/markup"
+)
+
+const stylesheet = `body { max-width: 40em; margin: 2em auto; padding: 0 1em; font-family: Georgia, serif; line-height: 1.6; }
+h1, h2, h3 { font-family: sans-serif; line-height: 1.2; }
+nav li { margin: 0.2em 0; }
+article { margin-bottom: 3em; }
+.byline { color: #666; font-style: italic; }
+img { max-width: 100%; height: auto; }
+pre { overflow-x: auto; padding: 0.5em; background: #f4f4f4; }
+blockquote { margin-left: 0; padding-left: 1em; border-left: 3px solid #ccc; color: #444; }
+`
+
+// chapter is a section as the templates see it, with post content already
+// sanitized.
+type chapter struct {
+	ID      string
+	Title   string
+	SiteURL string
+	Posts   []chapterPost
+}
+
+type chapterPost struct {
+	ID      string
+	Title   string
+	Link    string
+	Byline  string
+	Content template.HTML
+}
+
+func chapters(book Book, options markup.SanitizeOptions) []chapter {
+	chapters := make([]chapter, len(book.Sections))
+	for i, section := range book.Sections {
+		chapters[i] = chapter{
+			ID:      fmt.Sprintf("feed-%d", i+1),
+			Title:   section.Title,
+			SiteURL: section.SiteURL,
+		}
+		for j, post := range section.Posts {
+			options.Base = post.base()
+			chapters[i].Posts = append(chapters[i].Posts, chapterPost{
+				ID:     fmt.Sprintf("post-%d-%d", i+1, j+1),
+				Title:  post.Title,
+				Link:   post.Link,
+				Byline: post.byline(section),
+				// Sanitize leaves only markup that is safe to include as is
+				Content: template.HTML(markup.Sanitize(post.HTML, options)),
+			})
+		}
+	}
+	return chapters
+}
+
+var pageTemplate = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html lang="{{.Language}}">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="generator" content="gator">
+<title>{{.Title}}</title>
+<style>
+{{.Stylesheet}}</style>
+</head>
+<body>
+<header>
+<h1>{{.Title}}</h1>
+<p class="byline">Collected by {{.Author}} on {{.Created}}</p>
+</header>
+<nav>
+<h2>Contents</h2>
+<ul>
+{{- range .Chapters}}
+<li><a href="#{{.ID}}">{{.Title}}</a>
+<ul>
+{{- range .Posts}}
+<li><a href="#{{.ID}}">{{.Title}}</a></li>
+{{- end}}
+</ul>
+</li>
+{{- end}}
+</ul>
+</nav>
+{{- range .Chapters}}
+<section id="{{.ID}}">
+<h2>{{if .SiteURL}}<a href="{{.SiteURL}}">{{.Title}}</a>{{else}}{{.Title}}{{end}}</h2>
+{{- range .Posts}}
+<article id="{{.ID}}">
+<h3>{{if .Link}}<a href="{{.Link}}">{{.Title}}</a>{{else}}{{.Title}}{{end}}</h3>
+<p class="byline">{{.Byline}}</p>
+{{.Content}}
+</article>
+{{- end}}
+</section>
+{{- end}}
+</body>
+</html>
+`))
+
+// WriteHTML writes the book as a single HTML page that needs nothing else to
+// display, apart from the images posts link to.
+func WriteHTML(w io.Writer, book Book) error {
+	return pageTemplate.Execute(w, map[string]any{
+		"Title":      book.Title,
+		"Author":     book.Author,
+		"Language":   book.Language,
+		"Created":    book.Created.Format("January 2, 2006"),
+		"Stylesheet": template.CSS(stylesheet),
+		"Chapters":   chapters(book, markup.SanitizeOptions{}),
+	})
+}
@@ -0,0 +1,43 @@
+package bundle
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jdwalkerzhere/gator/internal/markup"
+)
+
+// WriteMarkdown writes the book as one Markdown document, starting with a
+// list of its contents.
+func WriteMarkdown(w io.Writer, book Book) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", markup.EscapeMarkdown(book.Title))
+	fmt.Fprintf(&b, "*Collected by %s on %s*\n\n", markup.EscapeMarkdown(book.Author), book.Created.Format("January 2, 2006"))
+	b.WriteString("## Contents\n\n")
+	for _, section := range book.Sections {
+		fmt.Fprintf(&b, "- %s (%d)\n", markup.EscapeMarkdown(section.Title), len(section.Posts))
+		for _, post := range section.Posts {
+			fmt.Fprintf(&b, "  - %s\n", markup.EscapeMarkdown(post.Title))
+		}
+	}
+
+	for _, section := range book.Sections {
+		fmt.Fprintf(&b, "\n## %s\n", markup.EscapeMarkdown(section.Title))
+		for _, post := range section.Posts {
+			fmt.Fprintf(&b, "\n### %s\n\n", markup.EscapeMarkdown(post.Title))
+			fmt.Fprintf(&b, "*%s*", markup.EscapeMarkdown(post.byline(section)))
+			if post.Link != "" {
+				fmt.Fprintf(&b, " · [Original](%s)", post.Link)
+			}
+			b.WriteString("\n")
+			// the post's own headings go below its title
+			content := markup.Markdown(post.HTML, post.base(), 3)
+			if content != "" {
+				b.WriteString("\n" + content + "\n")
+			}
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
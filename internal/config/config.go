@@ -3,11 +3,13 @@ package config
 import (
 	"encoding/json"
 	"os"
+	"path/filepath"
 )
 
 type Config struct {
-	DbURL       string `json:"db_url"`
-	CurrentUser string `json:"current_user_name"`
+	DbURL        string `json:"db_url"`
+	CurrentUser  string `json:"current_user_name"`
+	StatusSocket string `json:"status_socket,omitempty"`
 }
 
 func Read() (Config, error) {
@@ -36,6 +38,10 @@ func Read() (Config, error) {
 
 func (c *Config) SetUser(user string) error {
 	c.CurrentUser = user
+	return c.write()
+}
+
+func (c *Config) write() error {
 	homeDir, err := os.UserHomeDir()
 
 	if err != nil {
@@ -56,3 +62,26 @@ func (c *Config) SetUser(user string) error {
 
 	return nil
 }
+
+// StatusSocketPath is where agg answers status requests. By default it lives
+// in the user's runtime directory, falling back to gator's configuration
+// directory named after the host, so that neither other users nor other
+// machines sharing a home directory can reach or replace it.
+func (c *Config) StatusSocketPath() (string, error) {
+	if c.StatusSocket != "" {
+		return c.StatusSocket, nil
+	}
+	if runtimeDir := os.Getenv("XDG_RUNTIME_DIR"); runtimeDir != "" {
+		return filepath.Join(runtimeDir, "gator-agg.sock"), nil
+	}
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	host, err := os.Hostname()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "gator", "agg-"+host+".sock"), nil
+}
@@ -7,6 +7,7 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
@@ -22,7 +23,7 @@ VALUES (
     $5,
     $6
 )
-RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, hub_url, hub_topic, language, retention_days, retention_posts, site_url
 `
 
 type CreateFeedParams struct {
@@ -52,12 +53,38 @@ func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, e
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.HubUrl,
+		&i.HubTopic,
+		&i.Language,
+		&i.RetentionDays,
+		&i.RetentionPosts,
+		&i.SiteUrl,
 	)
 	return i, err
 }
 
+const detectFeedLanguage = `-- name: DetectFeedLanguage :execrows
+UPDATE feeds
+SET
+    language = $2
+WHERE id = $1 AND language IS NULL
+`
+
+type DetectFeedLanguageParams struct {
+	ID       uuid.UUID
+	Language sql.NullString
+}
+
+func (q *Queries) DetectFeedLanguage(ctx context.Context, arg DetectFeedLanguageParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, detectFeedLanguage, arg.ID, arg.Language)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const getFeed = `-- name: GetFeed :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, hub_url, hub_topic, language, retention_days, retention_posts, site_url FROM feeds
 WHERE url = $1
 `
 
@@ -72,10 +99,85 @@ func (q *Queries) GetFeed(ctx context.Context, url string) (Feed, error) {
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.HubUrl,
+		&i.HubTopic,
+		&i.Language,
+		&i.RetentionDays,
+		&i.RetentionPosts,
+		&i.SiteUrl,
+	)
+	return i, err
+}
+
+const getFeedByID = `-- name: GetFeedByID :one
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, hub_url, hub_topic, language, retention_days, retention_posts, site_url FROM feeds
+WHERE id = $1
+`
+
+func (q *Queries) GetFeedByID(ctx context.Context, id uuid.UUID) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, getFeedByID, id)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.HubUrl,
+		&i.HubTopic,
+		&i.Language,
+		&i.RetentionDays,
+		&i.RetentionPosts,
+		&i.SiteUrl,
 	)
 	return i, err
 }
 
+const getFeedFetchQueue = `-- name: GetFeedFetchQueue :many
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, hub_url, hub_topic, language, retention_days, retention_posts, site_url
+FROM feeds
+ORDER BY last_fetched_at NULLS FIRST, updated_at
+`
+
+func (q *Queries) GetFeedFetchQueue(ctx context.Context) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, getFeedFetchQueue)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Feed
+	for rows.Next() {
+		var i Feed
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.LastFetchedAt,
+			&i.HubUrl,
+			&i.HubTopic,
+			&i.Language,
+			&i.RetentionDays,
+			&i.RetentionPosts,
+			&i.SiteUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getFeeds = `-- name: GetFeeds :many
 SELECT
     f.name AS feed_name,
@@ -114,8 +216,51 @@ func (q *Queries) GetFeeds(ctx context.Context) ([]GetFeedsRow, error) {
 	return items, nil
 }
 
+const getFeedsWithHub = `-- name: GetFeedsWithHub :many
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, hub_url, hub_topic, language, retention_days, retention_posts, site_url
+FROM feeds
+WHERE hub_url IS NOT NULL
+`
+
+func (q *Queries) GetFeedsWithHub(ctx context.Context) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, getFeedsWithHub)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Feed
+	for rows.Next() {
+		var i Feed
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.LastFetchedAt,
+			&i.HubUrl,
+			&i.HubTopic,
+			&i.Language,
+			&i.RetentionDays,
+			&i.RetentionPosts,
+			&i.SiteUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getNextFeedToFetch = `-- name: GetNextFeedToFetch :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, hub_url, hub_topic, language, retention_days, retention_posts, site_url
 FROM feeds
 ORDER BY last_fetched_at NULLS FIRST, updated_at
 LIMIT 1
@@ -132,6 +277,12 @@ func (q *Queries) GetNextFeedToFetch(ctx context.Context) (Feed, error) {
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.HubUrl,
+		&i.HubTopic,
+		&i.Language,
+		&i.RetentionDays,
+		&i.RetentionPosts,
+		&i.SiteUrl,
 	)
 	return i, err
 }
@@ -147,3 +298,55 @@ func (q *Queries) MarkFeedFetched(ctx context.Context, id uuid.UUID) error {
 	_, err := q.db.ExecContext(ctx, markFeedFetched, id)
 	return err
 }
+
+const setFeedHub = `-- name: SetFeedHub :exec
+UPDATE feeds
+SET
+    (hub_url, hub_topic) = ($2, $3)
+WHERE id = $1
+`
+
+type SetFeedHubParams struct {
+	ID       uuid.UUID
+	HubUrl   sql.NullString
+	HubTopic sql.NullString
+}
+
+func (q *Queries) SetFeedHub(ctx context.Context, arg SetFeedHubParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedHub, arg.ID, arg.HubUrl, arg.HubTopic)
+	return err
+}
+
+const setFeedLanguage = `-- name: SetFeedLanguage :exec
+UPDATE feeds
+SET
+    (updated_at, language) = (NOW(), $2)
+WHERE id = $1
+`
+
+type SetFeedLanguageParams struct {
+	ID       uuid.UUID
+	Language sql.NullString
+}
+
+func (q *Queries) SetFeedLanguage(ctx context.Context, arg SetFeedLanguageParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedLanguage, arg.ID, arg.Language)
+	return err
+}
+
+const setFeedSiteURL = `-- name: SetFeedSiteURL :exec
+UPDATE feeds
+SET
+    site_url = $2
+WHERE id = $1
+`
+
+type SetFeedSiteURLParams struct {
+	ID      uuid.UUID
+	SiteUrl sql.NullString
+}
+
+func (q *Queries) SetFeedSiteURL(ctx context.Context, arg SetFeedSiteURLParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedSiteURL, arg.ID, arg.SiteUrl)
+	return err
+}
@@ -11,14 +11,27 @@ import (
 	"github.com/google/uuid"
 )
 
+type DefaultRetention struct {
+	ID        bool
+	UpdatedAt time.Time
+	Days      int32
+	Posts     int32
+}
+
 type Feed struct {
-	ID            uuid.UUID
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
-	Name          string
-	Url           string
-	UserID        uuid.UUID
-	LastFetchedAt sql.NullTime
+	ID             uuid.UUID
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	Name           string
+	Url            string
+	UserID         uuid.UUID
+	LastFetchedAt  sql.NullTime
+	HubUrl         sql.NullString
+	HubTopic       sql.NullString
+	Language       sql.NullString
+	RetentionDays  sql.NullInt32
+	RetentionPosts sql.NullInt32
+	SiteUrl        sql.NullString
 }
 
 type FeedFollow struct {
@@ -29,6 +42,32 @@ type FeedFollow struct {
 	FeedID    uuid.UUID
 }
 
+type FetchLog struct {
+	ID         uuid.UUID
+	FeedID     uuid.UUID
+	StartedAt  time.Time
+	FinishedAt time.Time
+	HttpStatus sql.NullInt32
+	Bytes      int64
+	ItemsSeen  int32
+	NewPosts   int32
+	Error      sql.NullString
+}
+
+type Folder struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UserID    uuid.UUID
+	Name      string
+}
+
+type FolderFeed struct {
+	FolderID     uuid.UUID
+	FeedFollowID uuid.UUID
+	CreatedAt    time.Time
+}
+
 type Post struct {
 	ID          uuid.UUID
 	CreatedAt   time.Time
@@ -38,6 +77,71 @@ type Post struct {
 	Description string
 	PublishedAt time.Time
 	FeedID      uuid.UUID
+	Author      string
+	Categories  []string
+	Content     string
+	Guid        string
+}
+
+type PostSearch struct {
+	PostID   uuid.UUID
+	Language interface{}
+	Document interface{}
+	Text     string
+}
+
+type PostState struct {
+	UserID    uuid.UUID
+	PostID    uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Read      bool
+	ReadAt    sql.NullTime
+	Starred   bool
+	StarredAt sql.NullTime
+	Hidden    bool
+}
+
+type PostTombstone struct {
+	FeedID   uuid.UUID
+	Guid     string
+	PrunedAt time.Time
+}
+
+type ReadLater struct {
+	UserID    uuid.UUID
+	PostID    uuid.UUID
+	CreatedAt time.Time
+	Position  int32
+}
+
+type Rule struct {
+	ID                 uuid.UUID
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+	UserID             uuid.UUID
+	Action             string
+	FeedID             uuid.NullUUID
+	TitlePattern       sql.NullString
+	DescriptionPattern sql.NullString
+	Author             sql.NullString
+	Category           sql.NullString
+}
+
+type RuleMatch struct {
+	RuleID uuid.UUID
+	UserID uuid.UUID
+	Action string
+	PostID uuid.UUID
+}
+
+type SavedSearch struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UserID    uuid.UUID
+	Name      string
+	Query     string
 }
 
 type User struct {
@@ -46,3 +150,15 @@ type User struct {
 	UpdatedAt time.Time
 	Name      string
 }
+
+type WebsubSubscription struct {
+	ID             uuid.UUID
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	FeedID         uuid.UUID
+	HubUrl         string
+	TopicUrl       string
+	Secret         string
+	LeaseExpiresAt sql.NullTime
+	PendingMode    sql.NullString
+}
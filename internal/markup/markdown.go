@@ -0,0 +1,285 @@
+package markup
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var blockTags = map[string]bool{
+	"address":    true,
+	"article":    true,
+	"aside":      true,
+	"blockquote": true,
+	"dd":         true,
+	"div":        true,
+	"dl":         true,
+	"dt":         true,
+	"figcaption": true,
+	"figure":     true,
+	"footer":     true,
+	"h1":         true,
+	"h2":         true,
+	"h3":         true,
+	"h4":         true,
+	"h5":         true,
+	"h6":         true,
+	"header":     true,
+	"hr":         true,
+	"li":         true,
+	"main":       true,
+	"nav":        true,
+	"ol":         true,
+	"p":          true,
+	"pre":        true,
+	"section":    true,
+	"table":      true,
+	"ul":         true,
+}
+
+var listMarker = regexp.MustCompile(`^(-|\d+\.) `)
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"*", `\*`,
+	"_", `\_`,
+	"`", "\\`",
+	"[", `\[`,
+	"]", `\]`,
+	"<", `\<`,
+)
+
+// EscapeMarkdown escapes the characters in plain text that Markdown would
+// read as formatting.
+func EscapeMarkdown(text string) string {
+	return markdownEscaper.Replace(text)
+}
+
+// lineBreak is a Markdown hard line break.
+const lineBreak = "  \n"
+
+type markdownOptions struct {
+	base *url.URL
+	// headingLevel is added to the level of every heading, so the post's
+	// own headings sit under the title it is exported with.
+	headingLevel int
+}
+
+// Markdown converts an HTML fragment to Markdown. Headings are pushed down
+// headingLevel levels.
+func Markdown(html string, base *url.URL, headingLevel int) string {
+	options := markdownOptions{base: base, headingLevel: headingLevel}
+	return strings.Join(options.blocks(parse(html)), "\n\n")
+}
+
+// blocks renders the children of n as Markdown blocks, wrapping runs of
+// inline content in paragraphs.
+func (o markdownOptions) blocks(n *node) []string {
+	var blocks []string
+	var paragraph strings.Builder
+	flush := func() {
+		lines := strings.Split(paragraph.String(), lineBreak)
+		for i, line := range lines {
+			lines[i] = strings.TrimSpace(collapseSpace(line))
+		}
+		text := strings.TrimSpace(strings.Join(lines, lineBreak))
+		if text != "" {
+			blocks = append(blocks, text)
+		}
+		paragraph.Reset()
+	}
+
+	for _, child := range n.children {
+		if dropped[child.tag] {
+			continue
+		}
+		if !blockTags[child.tag] {
+			paragraph.WriteString(o.inline(child))
+			continue
+		}
+		flush()
+		if block := o.block(child); block != "" {
+			blocks = append(blocks, block)
+		}
+	}
+	flush()
+	return blocks
+}
+
+func (o markdownOptions) block(n *node) string {
+	switch n.tag {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		level := min(int(n.tag[1]-'0')+o.headingLevel, 6)
+		text := strings.TrimSpace(o.inlineChildren(n))
+		if text == "" {
+			return ""
+		}
+		return strings.Repeat("#", level) + " " + text
+	case "hr":
+		return "---"
+	case "pre":
+		code := strings.Trim(n.textContent(), "\n")
+		fence := "```"
+		for strings.Contains(code, fence) {
+			fence += "`"
+		}
+		return fence + "\n" + code + "\n" + fence
+	case "blockquote":
+		return prefixLines(strings.Join(o.blocks(n), "\n\n"), "> ", ">")
+	case "ul", "ol":
+		return o.list(n)
+	case "table":
+		return o.table(n)
+	default:
+		return strings.Join(o.blocks(n), "\n\n")
+	}
+}
+
+func (o markdownOptions) list(n *node) string {
+	number := 1
+	if start, err := strconv.Atoi(n.attrs["start"]); err == nil {
+		number = start
+	}
+	var items []string
+	for _, child := range n.children {
+		if child.tag != "li" {
+			continue
+		}
+		marker := "- "
+		if n.tag == "ol" {
+			marker = strconv.Itoa(number) + ". "
+			number++
+		}
+		content := ""
+		for i, block := range o.blocks(child) {
+			// keep nested lists tight
+			if i > 0 && listMarker.MatchString(block) {
+				content += "\n"
+			} else if i > 0 {
+				content += "\n\n"
+			}
+			content += block
+		}
+		indent := strings.Repeat(" ", len(marker))
+		items = append(items, marker+strings.TrimPrefix(prefixLines(content, indent, ""), indent))
+	}
+	return strings.Join(items, "\n")
+}
+
+func (o markdownOptions) table(n *node) string {
+	var rows [][]string
+	var collect func(*node)
+	collect = func(n *node) {
+		for _, child := range n.children {
+			switch child.tag {
+			case "tr":
+				var cells []string
+				for _, cell := range child.children {
+					if cell.tag == "td" || cell.tag == "th" {
+						text := strings.TrimSpace(o.inlineChildren(cell))
+						cells = append(cells, strings.ReplaceAll(text, "|", `\|`))
+					}
+				}
+				rows = append(rows, cells)
+			case "thead", "tbody", "tfoot":
+				collect(child)
+			}
+		}
+	}
+	collect(n)
+	if len(rows) == 0 {
+		return ""
+	}
+
+	columns := 0
+	for _, row := range rows {
+		columns = max(columns, len(row))
+	}
+	lines := make([]string, 0, len(rows)+1)
+	for i, row := range rows {
+		for len(row) < columns {
+			row = append(row, "")
+		}
+		lines = append(lines, "| "+strings.Join(row, " | ")+" |")
+		if i == 0 {
+			lines = append(lines, "|"+strings.Repeat(" --- |", columns))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (o markdownOptions) inlineChildren(n *node) string {
+	var b strings.Builder
+	for _, child := range n.children {
+		if !dropped[child.tag] {
+			b.WriteString(o.inline(child))
+		}
+	}
+	return b.String()
+}
+
+func (o markdownOptions) inline(n *node) string {
+	switch n.tag {
+	case "":
+		return markdownEscaper.Replace(collapseSpace(n.text))
+	case "br":
+		return lineBreak
+	case "em", "i", "cite":
+		return wrapInline(o.inlineChildren(n), "*")
+	case "strong", "b":
+		return wrapInline(o.inlineChildren(n), "**")
+	case "del", "s":
+		return wrapInline(o.inlineChildren(n), "~~")
+	case "code", "kbd":
+		code := n.textContent()
+		fence := "`"
+		for strings.Contains(code, fence) {
+			fence += "`"
+		}
+		return fence + code + fence
+	case "a":
+		text := o.inlineChildren(n)
+		href := resolve(n.attrs["href"], o.base)
+		if href == "" || strings.TrimSpace(text) == "" {
+			return text
+		}
+		return "[" + strings.TrimSpace(text) + "](" + href + ")"
+	case "img":
+		src := resolve(n.attrs["src"], o.base)
+		if src == "" {
+			return ""
+		}
+		return "![" + markdownEscaper.Replace(n.attrs["alt"]) + "](" + src + ")"
+	default:
+		if blockTags[n.tag] {
+			// block content inside inline content can't be nested in Markdown
+			return " " + strings.Join(o.blocks(n), " ") + " "
+		}
+		return o.inlineChildren(n)
+	}
+}
+
+// wrapInline puts emphasis markers around text, outside any surrounding
+// spaces, which Markdown won't accept inside them.
+func wrapInline(text, marker string) string {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return text
+	}
+	start := text[:strings.Index(text, trimmed)]
+	end := text[len(start)+len(trimmed):]
+	return start + marker + trimmed + marker + end
+}
+
+func prefixLines(text, prefix, emptyPrefix string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line == "" {
+			lines[i] = emptyPrefix
+		} else {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,54 @@
+package markup
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestMarkdown(t *testing.T) {
+	base, err := url.Parse("https://example.com/posts/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name         string
+		html         string
+		headingLevel int
+		want         string
+	}{
+		{name: "paragraphs", html: "<p>one</p><p>two\n  lines</p>", want: "one\n\ntwo lines"},
+		{name: "loose text", html: "one <b>two</b><p>three</p>four", want: "one **two**\n\nthree\n\nfour"},
+		{name: "emphasis", html: "<em>a</em> <strong> b </strong> <del>c</del>", want: "*a* **b** ~~c~~"},
+		{name: "escaped text", html: "2 * 3_000 [x] <tag>", want: `2 \* 3\_000 \[x\]`},
+		{name: "escaped entity", html: "a &lt;b&gt;", want: `a \<b>`},
+		{name: "headings pushed down", html: "<h1>Title</h1><h5>Deep</h5><h6>Deeper</h6>", headingLevel: 1, want: "## Title\n\n###### Deep\n\n###### Deeper"},
+		{name: "empty heading", html: "<h2> </h2>text", want: "text"},
+		{name: "link", html: `<a href="/about">About us</a>`, want: "[About us](https://example.com/about)"},
+		{name: "javascript link", html: `<a href="javascript:alert(1)">x</a>`, want: "x"},
+		{name: "image", html: `<img src="a.png" alt="a_b">`, want: `![a\_b](https://example.com/posts/a.png)`},
+		{name: "line break", html: "a<br>b", want: "a  \nb"},
+		{name: "inline code", html: "<code>a `b` c</code>", want: "``a `b` c``"},
+		{name: "code block", html: "<pre>func main() {\n\tfmt.Println(\"*\")\n}</pre>", want: "```\nfunc main() {\n\tfmt.Println(\"*\")\n}\n```"},
+		{name: "code block with a fence", html: "<pre>```\ncode\n```</pre>", want: "````\n```\ncode\n```\n````"},
+		{name: "quote", html: "<blockquote><p>a</p><p>b</p></blockquote>", want: "> a\n>\n> b"},
+		{name: "lists", html: `<ul><li>a</li><li>b</li></ul><ol start="3"><li>c</li><li>d</li></ol>`, want: "- a\n- b\n\n3. c\n4. d"},
+		{name: "nested lists", html: "<ul><li>a<ul><li>b<ol><li>c</li></ol></li></ul></li><li>d</li></ul>", want: "- a\n  - b\n    1. c\n- d"},
+		{name: "list item paragraphs", html: "<ol><li><p>a</p><p>b</p></li></ol>", want: "1. a\n\n   b"},
+		{name: "table", html: "<table><tr><th>a</th><th>b</th></tr><tr><td>1|2</td></tr></table>", want: "| a | b |\n| --- | --- |\n| 1\\|2 |  |"},
+		{name: "dropped elements", html: "<p>a</p><script>b()</script><style>c{}</style>", want: "a"},
+		{name: "rule", html: "a<hr>b", want: "a\n\n---\n\nb"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Markdown(tt.html, base, tt.headingLevel); got != tt.want {
+				t.Errorf("Markdown(%q) =\n%s\nwant\n%s", tt.html, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEscapeMarkdown(t *testing.T) {
+	if got, want := EscapeMarkdown(`a*b_c[d]e\f`+"`g`<h"), `a\*b\_c\[d\]e\\f`+"\\`g\\`"+`\<h`; got != want {
+		t.Errorf("EscapeMarkdown = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,290 @@
+// Package markup turns the HTML found in feeds, which is rarely well formed,
+// into something safe to show elsewhere: sanitized XHTML, Markdown, or text
+// for a terminal.
+package markup
+
+import (
+	"html"
+	"strings"
+)
+
+// node is an element or, when tag is empty, a run of text.
+type node struct {
+	tag      string
+	attrs    map[string]string
+	text     string
+	children []*node
+}
+
+// dropped elements are removed along with everything inside them.
+var dropped = map[string]bool{
+	"script":   true,
+	"style":    true,
+	"iframe":   true,
+	"object":   true,
+	"embed":    true,
+	"form":     true,
+	"button":   true,
+	"select":   true,
+	"textarea": true,
+	"noscript": true,
+	"svg":      true,
+	"head":     true,
+	"title":    true,
+	"template": true,
+}
+
+// voidTags never have content or an end tag.
+var voidTags = map[string]bool{
+	"area":   true,
+	"base":   true,
+	"br":     true,
+	"col":    true,
+	"embed":  true,
+	"hr":     true,
+	"img":    true,
+	"input":  true,
+	"link":   true,
+	"meta":   true,
+	"param":  true,
+	"source": true,
+	"track":  true,
+	"wbr":    true,
+}
+
+// rawTextTags hold text that isn't markup, up to their end tag.
+var rawTextTags = map[string]bool{
+	"script":   true,
+	"style":    true,
+	"textarea": true,
+	"title":    true,
+}
+
+// impliedEnds lists, for the elements that close an open element of the
+// same kind without an end tag, the elements that stop the search.
+var impliedEnds = map[string]map[string]bool{
+	"li": {"ul": true, "ol": true},
+	"dt": {"dl": true},
+	"dd": {"dl": true},
+	"tr": {"table": true, "thead": true, "tbody": true, "tfoot": true},
+	"td": {"tr": true, "table": true},
+	"th": {"tr": true, "table": true},
+}
+
+// paragraphStops are the elements a block element doesn't close an open p
+// beyond.
+var paragraphStops = map[string]bool{
+	"article":    true,
+	"blockquote": true,
+	"div":        true,
+	"li":         true,
+	"section":    true,
+	"td":         true,
+	"th":         true,
+}
+
+// parse builds a tree out of an HTML fragment the way a browser would
+// tolerate it: unclosed elements are closed by their parent, stray end tags
+// are ignored, and a < that doesn't start a tag is text.
+func parse(source string) *node {
+	root := &node{tag: "root"}
+	stack := []*node{root}
+	appendNode := func(n *node) {
+		parent := stack[len(stack)-1]
+		parent.children = append(parent.children, n)
+	}
+	appendText := func(text string) {
+		if text != "" {
+			appendNode(&node{text: html.UnescapeString(text)})
+		}
+	}
+
+	for len(source) > 0 {
+		next := strings.IndexByte(source, '<')
+		if next < 0 {
+			appendText(source)
+			break
+		}
+		appendText(source[:next])
+		source = source[next:]
+
+		switch {
+		case strings.HasPrefix(source, "<!--"):
+			source = skipPast(source, "-->")
+		case strings.HasPrefix(source, "<![CDATA["):
+			end := strings.Index(source, "]]>")
+			if end < 0 {
+				end = len(source)
+			}
+			appendNode(&node{text: source[len("<![CDATA["):end]})
+			source = skipPast(source, "]]>")
+		case strings.HasPrefix(source, "<!"), strings.HasPrefix(source, "<?"):
+			source = skipPast(source, ">")
+		case strings.HasPrefix(source, "</") && len(source) > 2 && isLetter(source[2]):
+			name, _ := readName(source[2:])
+			source = skipPast(source, ">")
+			// close the nearest open element of this kind, if there is one
+			for i := len(stack) - 1; i > 0; i-- {
+				if stack[i].tag == name {
+					stack = stack[:i]
+					break
+				}
+			}
+		case len(source) > 1 && isLetter(source[1]):
+			var element *node
+			var selfClosing bool
+			element, selfClosing, source = readStartTag(source)
+			if blockTags[element.tag] {
+				stack = closeOpen(stack, "p", paragraphStops)
+			}
+			if stops, ok := impliedEnds[element.tag]; ok {
+				stack = closeOpen(stack, element.tag, stops)
+			}
+			appendNode(element)
+			if rawTextTags[element.tag] && !selfClosing {
+				end := indexFold(source, "</"+element.tag)
+				if end < 0 {
+					end = len(source)
+				}
+				element.children = append(element.children, &node{text: html.UnescapeString(source[:end])})
+				source = skipPast(source[end:], ">")
+				continue
+			}
+			if !voidTags[element.tag] && !selfClosing {
+				stack = append(stack, element)
+			}
+		default:
+			appendText("<")
+			source = source[1:]
+		}
+	}
+	return root
+}
+
+// closeOpen closes the innermost open tag element, unless one of the stops
+// is open inside it.
+func closeOpen(stack []*node, tag string, stops map[string]bool) []*node {
+	for i := len(stack) - 1; i > 0; i-- {
+		if stack[i].tag == tag {
+			return stack[:i]
+		}
+		if stops[stack[i].tag] {
+			break
+		}
+	}
+	return stack
+}
+
+func readStartTag(source string) (*node, bool, string) {
+	name, rest := readName(source[1:])
+	element := &node{tag: name, attrs: make(map[string]string)}
+	for {
+		rest = strings.TrimLeft(rest, " \t\r\n\f")
+		switch {
+		case rest == "":
+			return element, false, rest
+		case rest[0] == '>':
+			return element, false, rest[1:]
+		case strings.HasPrefix(rest, "/>"):
+			return element, true, rest[2:]
+		case rest[0] == '/':
+			rest = rest[1:]
+			continue
+		}
+
+		end := strings.IndexAny(rest, " \t\r\n\f=/>")
+		if end < 0 {
+			end = len(rest)
+		}
+		if end == 0 {
+			// a lone = or quote, skip it
+			end = 1
+		}
+		attr := strings.ToLower(rest[:end])
+		rest = strings.TrimLeft(rest[end:], " \t\r\n\f")
+		value := ""
+		if strings.HasPrefix(rest, "=") {
+			rest = strings.TrimLeft(rest[1:], " \t\r\n\f")
+			value, rest = readAttrValue(rest)
+		}
+		if _, ok := element.attrs[attr]; !ok {
+			element.attrs[attr] = html.UnescapeString(value)
+		}
+	}
+}
+
+func readAttrValue(text string) (string, string) {
+	if text != "" && (text[0] == '"' || text[0] == '\'') {
+		end := strings.IndexByte(text[1:], text[0])
+		if end < 0 {
+			return text[1:], ""
+		}
+		return text[1 : end+1], text[end+2:]
+	}
+	end := strings.IndexAny(text, " \t\r\n\f>")
+	if end < 0 {
+		end = len(text)
+	}
+	return text[:end], text[end:]
+}
+
+func readName(text string) (string, string) {
+	end := 0
+	for end < len(text) && (isLetter(text[end]) || (text[end] >= '0' && text[end] <= '9') || text[end] == '-' || text[end] == ':') {
+		end++
+	}
+	return strings.ToLower(text[:end]), text[end:]
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// skipPast drops text up to and including the first marker, or all of it.
+func skipPast(text, marker string) string {
+	end := strings.Index(text, marker)
+	if end < 0 {
+		return ""
+	}
+	return text[end+len(marker):]
+}
+
+func indexFold(text, substr string) int {
+	return strings.Index(strings.ToLower(text), strings.ToLower(substr))
+}
+
+// textContent is the text of a node and everything inside it.
+func (n *node) textContent() string {
+	if n.tag == "" {
+		return n.text
+	}
+	var b strings.Builder
+	for _, child := range n.children {
+		if !dropped[child.tag] {
+			b.WriteString(child.textContent())
+		}
+	}
+	return b.String()
+}
+
+// collapseSpace folds runs of whitespace into single spaces, as browsers do
+// outside of pre.
+func collapseSpace(text string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range text {
+		if r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '\f' {
+			space = true
+			continue
+		}
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		b.WriteRune(r)
+	}
+	if space {
+		b.WriteByte(' ')
+	}
+	return b.String()
+}
@@ -0,0 +1,115 @@
+package markup
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// dump writes a tree as tag(children) with text quoted, leaving out the root.
+func dump(n *node) string {
+	parts := make([]string, len(n.children))
+	for i, child := range n.children {
+		if child.tag == "" {
+			parts[i] = `"` + child.text + `"`
+			continue
+		}
+		parts[i] = child.tag + "(" + dump(child) + ")"
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{name: "text", source: "plain text", want: `"plain text"`},
+		{name: "nested", source: "<p>a <b>bold</b> word</p>", want: `p("a " b("bold") " word")`},
+		{name: "uppercase tags", source: "<P>a<BR>b</P>", want: `p("a" br() "b")`},
+		{name: "entities", source: "<p>fish &amp; chips &lt;3</p>", want: `p("fish & chips <3")`},
+		{name: "unclosed at the end", source: "<p>one<em>two", want: `p("one" em("two"))`},
+		{name: "paragraph closed by the next", source: "<p>one<p>two", want: `p("one") p("two")`},
+		{name: "paragraph closed by a block", source: "<p>one<ul><li>two</ul>", want: `p("one") ul(li("two"))`},
+		{name: "list items close each other", source: "<ul><li>a<li>b</ul>", want: `ul(li("a") li("b"))`},
+		{name: "nested list items", source: "<ul><li>a<ul><li>b<li>c</ul><li>d</ul>", want: `ul(li("a" ul(li("b") li("c"))) li("d"))`},
+		{name: "table cells close each other", source: "<table><tr><td>a<td>b<tr><td>c</table>", want: `table(tr(td("a") td("b")) tr(td("c")))`},
+		{name: "stray end tag", source: "a</b>c", want: `"a" "c"`},
+		{name: "end tag closes what is inside", source: "<div><b>a</div>b", want: `div(b("a")) "b"`},
+		{name: "lone angle bracket", source: "1 < 2 <3", want: `"1 " "<" " 2 " "<" "3"`},
+		{name: "comment", source: "a<!-- <b>hidden</b> -->b", want: `"a" "b"`},
+		{name: "unterminated comment", source: "a<!-- b", want: `"a"`},
+		{name: "cdata", source: "<![CDATA[<b>raw</b>]]>", want: `"<b>raw</b>"`},
+		{name: "doctype", source: "<!DOCTYPE html><p>a</p>", want: `p("a")`},
+		{name: "raw text", source: "<script>if (a < b) { x = '</p>' }</script>c", want: `script("if (a < b) { x = '</p>' }") "c"`},
+		{name: "self-closing", source: "a<br/>b<img src=x />", want: `"a" br() "b" img()`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dump(parse(tt.source)); got != tt.want {
+				t.Errorf("parse(%q) = %s, want %s", tt.source, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAttributes(t *testing.T) {
+	root := parse(`<a HREF="/one?a=1&amp;b=2" title='a "b"' data-x=bare checked href="/two">x</a>`)
+	link := root.children[0]
+	want := map[string]string{
+		"href":    "/one?a=1&b=2",
+		"title":   `a "b"`,
+		"data-x":  "bare",
+		"checked": "",
+	}
+	for name, value := range want {
+		if got, ok := link.attrs[name]; !ok || got != value {
+			t.Errorf("attribute %s = %q (set %v), want %q", name, got, ok, value)
+		}
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	base, err := url.Parse("https://example.com/posts/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		html    string
+		options SanitizeOptions
+		want    string
+	}{
+		{name: "kept formatting", html: "<p>a <strong>b</strong> <em>c</em></p>", want: "<p>a <strong>b</strong> <em>c</em></p>"},
+		{name: "script", html: "<p>a</p><script>alert(1)</script><p>b</p>", want: "<p>a</p><p>b</p>"},
+		{name: "style", html: "<style>p { color: red }</style>text", want: "text"},
+		{name: "uppercase script", html: "<SCRIPT>alert(1)</SCRIPT>ok", want: "ok"},
+		{name: "unterminated script", html: "ok<script>alert(1)", want: "ok"},
+		{name: "iframe", html: `<iframe src="https://evil.example"><p>x</p></iframe>y`, want: "y"},
+		{name: "event attributes", html: `<p onclick="alert(1)">a</p><img src="/a.png" onerror="alert(1)" alt="A">`, options: SanitizeOptions{Base: base}, want: `<p>a</p><img alt="A" src="https://example.com/a.png"/>`},
+		{name: "style attribute", html: `<b style="position:fixed">a</b>`, want: "<b>a</b>"},
+		{name: "javascript link", html: `<a href="javascript:alert(1)">a</a>`, want: "<a>a</a>"},
+		{name: "javascript link with spaces and case", html: `<a href=" JavaScript:alert(1)">a</a>`, want: "<a>a</a>"},
+		{name: "data link", html: `<a href="data:text/html,<script>alert(1)</script>">a</a>`, want: "<a>a</a>"},
+		{name: "javascript image", html: `<img src="javascript:alert(1)">a`, want: "a"},
+		{name: "relative link", html: `<a href="../2" title="Next">b</a>`, options: SanitizeOptions{Base: base}, want: `<a href="https://example.com/2" title="Next">b</a>`},
+		{name: "fragment link", html: `<a href="#top">top</a>`, want: "<a>top</a>"},
+		{name: "mailto link", html: `<a href="mailto:a@example.com">mail</a>`, want: `<a href="mailto:a@example.com">mail</a>`},
+		{name: "unknown tags unwrapped", html: "<section><span>a</span></section>", want: "a"},
+		{name: "unclosed tags", html: "<p>a <b>b <i>c", want: "<p>a <b>b <i>c</i></b></p>"},
+		{name: "misnested tags", html: "<b>a<i>b</b>c</i>", want: "<b>a<i>b</i></b>c"},
+		{name: "void tags", html: "a<br>b<hr>", want: "a<br/>b<hr/>"},
+		{name: "escaped text", html: "a &lt;b&gt; &amp; c", want: "a &lt;b&gt; &amp; c"},
+		{name: "escaped attribute", html: `<a href="https://example.com/?q=&quot;x&quot;" title="a &amp; b">x</a>`, want: `<a href="https://example.com/?q=&#34;x&#34;" title="a &amp; b">x</a>`},
+		{name: "images as alt text", html: `<img src="/a.png" alt="A chart">`, options: SanitizeOptions{Base: base, NoImages: true}, want: "[A chart]"},
+		{name: "ordered list start", html: `<ol start="3" type="a"><li>c</li></ol>`, want: `<ol start="3"><li>c</li></ol>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sanitize(tt.html, tt.options); got != tt.want {
+				t.Errorf("Sanitize(%q) = %q, want %q", tt.html, got, tt.want)
+			}
+		})
+	}
+}
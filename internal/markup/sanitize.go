@@ -0,0 +1,161 @@
+package markup
+
+import (
+	"encoding/xml"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// allowed lists the elements kept by Sanitize and the attributes each keeps.
+// Other elements are unwrapped, keeping their content.
+var allowed = map[string][]string{
+	"a":          {"href", "title"},
+	"abbr":       {"title"},
+	"b":          nil,
+	"blockquote": nil,
+	"br":         nil,
+	"caption":    nil,
+	"cite":       nil,
+	"code":       nil,
+	"dd":         nil,
+	"del":        nil,
+	"div":        nil,
+	"dl":         nil,
+	"dt":         nil,
+	"em":         nil,
+	"figcaption": nil,
+	"figure":     nil,
+	"h1":         nil,
+	"h2":         nil,
+	"h3":         nil,
+	"h4":         nil,
+	"h5":         nil,
+	"h6":         nil,
+	"hr":         nil,
+	"i":          nil,
+	"img":        {"src", "alt", "title"},
+	"ins":        nil,
+	"kbd":        nil,
+	"li":         nil,
+	"mark":       nil,
+	"ol":         {"start"},
+	"p":          nil,
+	"pre":        nil,
+	"q":          nil,
+	"s":          nil,
+	"small":      nil,
+	"strong":     nil,
+	"sub":        nil,
+	"sup":        nil,
+	"table":      nil,
+	"tbody":      nil,
+	"td":         {"colspan", "rowspan"},
+	"tfoot":      nil,
+	"th":         {"colspan", "rowspan"},
+	"thead":      nil,
+	"tr":         nil,
+	"u":          nil,
+	"ul":         nil,
+}
+
+var void = map[string]bool{
+	"br":  true,
+	"hr":  true,
+	"img": true,
+}
+
+type SanitizeOptions struct {
+	// Base resolves relative links and image sources.
+	Base *url.URL
+	// NoImages replaces images with their alt text, for documents that
+	// can't load them.
+	NoImages bool
+}
+
+// Sanitize rewrites an HTML fragment as well-formed XHTML, keeping only
+// formatting and links. Scripts, styles, event handlers and anything that
+// isn't an http(s) or mailto link are removed.
+func Sanitize(html string, options SanitizeOptions) string {
+	var b strings.Builder
+	writeSanitized(&b, parse(html), options)
+	return b.String()
+}
+
+func writeSanitized(b *strings.Builder, n *node, options SanitizeOptions) {
+	for _, child := range n.children {
+		switch {
+		case child.tag == "":
+			xml.EscapeText(b, []byte(child.text))
+		case dropped[child.tag]:
+		case child.tag == "img" && options.NoImages:
+			if alt := child.attrs["alt"]; alt != "" {
+				xml.EscapeText(b, []byte("["+alt+"]"))
+			}
+		case !isAllowed(child.tag):
+			writeSanitized(b, child, options)
+		default:
+			if child.tag == "img" && resolve(child.attrs["src"], options.Base) == "" {
+				continue
+			}
+			b.WriteString("<" + child.tag)
+			writeAttrs(b, child, options)
+			if void[child.tag] {
+				b.WriteString("/>")
+				continue
+			}
+			b.WriteString(">")
+			writeSanitized(b, child, options)
+			b.WriteString("</" + child.tag + ">")
+		}
+	}
+}
+
+func isAllowed(tag string) bool {
+	_, ok := allowed[tag]
+	return ok
+}
+
+func writeAttrs(b *strings.Builder, n *node, options SanitizeOptions) {
+	names := append([]string(nil), allowed[n.tag]...)
+	sort.Strings(names)
+	for _, name := range names {
+		value, ok := n.attrs[name]
+		if !ok {
+			continue
+		}
+		if name == "href" || name == "src" {
+			value = resolve(value, options.Base)
+			if value == "" {
+				continue
+			}
+		}
+		b.WriteString(" " + name + `="`)
+		xml.EscapeText(b, []byte(value))
+		b.WriteString(`"`)
+	}
+}
+
+// resolve returns the link as an absolute URL, or nothing when it isn't one
+// a reader should follow.
+func resolve(link string, base *url.URL) string {
+	link = strings.TrimSpace(link)
+	if link == "" {
+		return ""
+	}
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	if strings.HasPrefix(link, "#") {
+		return ""
+	}
+	if base != nil {
+		parsed = base.ResolveReference(parsed)
+	}
+	switch parsed.Scheme {
+	case "http", "https", "mailto":
+		return parsed.String()
+	}
+	return ""
+}
@@ -0,0 +1,564 @@
+package markup
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ANSI escape codes for the styles Text uses. Each style is turned off on
+// its own so it can nest inside the others.
+const (
+	styleBold      = "\x1b[1m"
+	styleNoBold    = "\x1b[22m"
+	styleItalic    = "\x1b[3m"
+	styleNoItalic  = "\x1b[23m"
+	styleUnderline = "\x1b[4m"
+	styleNoUnder   = "\x1b[24m"
+	styleStrike    = "\x1b[9m"
+	styleNoStrike  = "\x1b[29m"
+	styleCode      = "\x1b[36m"
+	styleNoCode    = "\x1b[39m"
+	styleDim       = "\x1b[2m"
+	styleNoDim     = "\x1b[22m"
+	styleReset     = "\x1b[0m"
+)
+
+// styleEnds pairs the code ending each style with the one starting it.
+var styleEnds = map[string]string{
+	styleNoBold:   styleBold,
+	styleNoItalic: styleItalic,
+	styleNoUnder:  styleUnderline,
+	styleNoStrike: styleStrike,
+	styleNoCode:   styleCode,
+}
+
+type TextOptions struct {
+	// Width wraps lines at this many characters. Zero leaves paragraphs on
+	// a single line.
+	Width int
+	// Base resolves relative links and image sources.
+	Base *url.URL
+	// Styled marks up emphasis, headings, links and code with ANSI escape
+	// codes.
+	Styled bool
+	// NoFootnotes leaves links and images unnumbered, with no URLs listed at
+	// the end, for text too short to carry footnotes.
+	NoFootnotes bool
+}
+
+// textBlock is a rendered block. Lists are kept tight against the text
+// before them inside list items.
+type textBlock struct {
+	lines []string
+	list  bool
+}
+
+type textRenderer struct {
+	options TextOptions
+	// links are the footnotes, numbered in order from 1.
+	links []string
+}
+
+// Text renders an HTML fragment as text for a terminal. Paragraphs are
+// wrapped, lists and quotes indented and preformatted text kept as it is.
+// Links and images are numbered, with their URLs listed as footnotes at the
+// end.
+func Text(html string, options TextOptions) string {
+	r := &textRenderer{options: options}
+	lines := joinBlocks(r.blocks(parse(html), options.Width), false)
+	if len(r.links) > 0 {
+		lines = append(lines, "")
+		for i, link := range r.links {
+			lines = append(lines, r.style(styleDim, "["+strconv.Itoa(i+1)+"] "+link, styleReset))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// joinBlocks puts a blank line between blocks, except before the lists in
+// a tight list item.
+func joinBlocks(blocks []textBlock, tight bool) []string {
+	var lines []string
+	for i, block := range blocks {
+		if i > 0 && !(tight && block.list) {
+			lines = append(lines, "")
+		}
+		lines = append(lines, block.lines...)
+	}
+	return lines
+}
+
+// blocks renders the children of n as blocks, wrapping runs of inline
+// content in paragraphs.
+func (r *textRenderer) blocks(n *node, width int) []textBlock {
+	var blocks []textBlock
+	var paragraph strings.Builder
+	flush := func() {
+		text := strings.TrimSpace(paragraph.String())
+		if text != "" {
+			blocks = append(blocks, textBlock{lines: r.wrap(text, width)})
+		}
+		paragraph.Reset()
+	}
+
+	for _, child := range n.children {
+		if dropped[child.tag] {
+			continue
+		}
+		if !blockTags[child.tag] {
+			paragraph.WriteString(r.inline(child))
+			continue
+		}
+		flush()
+		if lines := r.block(child, width); len(lines) > 0 {
+			blocks = append(blocks, textBlock{lines: lines, list: child.tag == "ul" || child.tag == "ol"})
+		}
+	}
+	flush()
+	return blocks
+}
+
+func (r *textRenderer) block(n *node, width int) []string {
+	switch n.tag {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		text := strings.TrimSpace(r.inlineChildren(n))
+		if text == "" {
+			return nil
+		}
+		if r.options.Styled {
+			start, end := styleBold, styleNoBold
+			if n.tag == "h1" || n.tag == "h2" {
+				start, end = styleBold+styleUnderline, styleNoUnder+styleNoBold
+			}
+			return r.wrap(start+text+end, width)
+		}
+		lines := r.wrap(text, width)
+		// underline the top headings the way plain text does
+		if n.tag == "h1" || n.tag == "h2" {
+			underline := "="
+			if n.tag == "h2" {
+				underline = "-"
+			}
+			longest := 0
+			for _, line := range lines {
+				longest = max(longest, visibleWidth(line))
+			}
+			lines = append(lines, strings.Repeat(underline, longest))
+		}
+		return lines
+	case "hr":
+		return []string{r.style(styleDim, strings.Repeat("─", ruleWidth(width)), styleReset)}
+	case "pre":
+		code := strings.Trim(cleanText(n.textContent(), true), "\n")
+		var lines []string
+		for _, line := range strings.Split(code, "\n") {
+			lines = append(lines, "    "+r.style(styleCode, line, styleNoCode))
+		}
+		return lines
+	case "blockquote":
+		bar := "> "
+		if r.options.Styled {
+			bar = styleDim + "│" + styleReset + " "
+		}
+		return prefixTextLines(joinBlocks(r.blocks(n, indented(width, 2)), false), bar, bar)
+	case "ul", "ol":
+		return r.list(n, width)
+	case "dl":
+		var lines []string
+		for _, child := range n.children {
+			switch child.tag {
+			case "dt":
+				text := strings.TrimSpace(r.inlineChildren(child))
+				lines = append(lines, r.wrap(r.style(styleBold, text, styleNoBold), width)...)
+			case "dd":
+				content := joinBlocks(r.blocks(child, indented(width, 4)), true)
+				lines = append(lines, prefixTextLines(content, "    ", "")...)
+			}
+		}
+		return lines
+	case "table":
+		return r.table(n, width)
+	default:
+		return joinBlocks(r.blocks(n, width), false)
+	}
+}
+
+func (r *textRenderer) list(n *node, width int) []string {
+	number := 1
+	if start, err := strconv.Atoi(n.attrs["start"]); err == nil {
+		number = start
+	}
+	var lines []string
+	for _, child := range n.children {
+		if child.tag != "li" {
+			continue
+		}
+		marker := "• "
+		if n.tag == "ol" {
+			marker = strconv.Itoa(number) + ". "
+			number++
+		}
+		indent := strings.Repeat(" ", utf8.RuneCountInString(marker))
+		content := joinBlocks(r.blocks(child, indented(width, len(indent))), true)
+		if len(content) == 0 {
+			content = []string{""}
+		}
+		lines = append(lines, marker+content[0])
+		lines = append(lines, prefixTextLines(content[1:], indent, "")...)
+	}
+	return lines
+}
+
+// table lines up the cells of each column, or, when the table is too wide
+// for the lines, lists each row's cells separated by bars.
+func (r *textRenderer) table(n *node, width int) []string {
+	var rows [][]string
+	var header []bool
+	var collect func(*node)
+	collect = func(n *node) {
+		for _, child := range n.children {
+			switch child.tag {
+			case "tr":
+				var cells []string
+				heading := true
+				for _, cell := range child.children {
+					if cell.tag == "td" || cell.tag == "th" {
+						cells = append(cells, strings.Join(strings.Fields(r.inlineChildren(cell)), " "))
+						heading = heading && cell.tag == "th"
+					}
+				}
+				rows = append(rows, cells)
+				header = append(header, heading && len(cells) > 0)
+			case "thead", "tbody", "tfoot":
+				collect(child)
+			}
+		}
+	}
+	collect(n)
+
+	var columns []int
+	for _, row := range rows {
+		for i, cell := range row {
+			if i == len(columns) {
+				columns = append(columns, 0)
+			}
+			columns[i] = max(columns[i], visibleWidth(cell))
+		}
+	}
+	total := 0
+	for _, column := range columns {
+		total += column + 2
+	}
+
+	var lines []string
+	for i, row := range rows {
+		if width > 0 && total-2 > width {
+			lines = append(lines, r.wrap(strings.Join(row, " | "), width)...)
+			continue
+		}
+		line := ""
+		for j, cell := range row {
+			if j < len(row)-1 {
+				cell += strings.Repeat(" ", columns[j]-visibleWidth(cell)+2)
+			}
+			line += cell
+		}
+		if header[i] {
+			line = r.style(styleBold, line, styleNoBold)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func (r *textRenderer) inlineChildren(n *node) string {
+	var b strings.Builder
+	for _, child := range n.children {
+		if !dropped[child.tag] {
+			b.WriteString(r.inline(child))
+		}
+	}
+	return b.String()
+}
+
+func (r *textRenderer) inline(n *node) string {
+	switch n.tag {
+	case "":
+		return collapseSpace(cleanText(n.text, false))
+	case "br":
+		return "\n"
+	case "em", "i", "cite":
+		return r.style(styleItalic, r.inlineChildren(n), styleNoItalic)
+	case "strong", "b":
+		return r.style(styleBold, r.inlineChildren(n), styleNoBold)
+	case "u", "ins":
+		return r.style(styleUnderline, r.inlineChildren(n), styleNoUnder)
+	case "del", "s":
+		return r.style(styleStrike, r.inlineChildren(n), styleNoStrike)
+	case "code", "kbd", "samp":
+		return r.style(styleCode, collapseSpace(cleanText(n.textContent(), false)), styleNoCode)
+	case "q":
+		return "“" + r.inlineChildren(n) + "”"
+	case "a":
+		text := r.inlineChildren(n)
+		href := resolve(n.attrs["href"], r.options.Base)
+		if href == "" {
+			return text
+		}
+		if strings.TrimSpace(text) == "" {
+			return href
+		}
+		// a link that shows its own address needs no footnote
+		if strings.TrimSpace(text) == href || strings.TrimSpace(text) == n.attrs["href"] {
+			return r.style(styleUnderline, text, styleNoUnder)
+		}
+		return r.style(styleUnderline, text, styleNoUnder) + r.footnote(href)
+	case "img":
+		alt := strings.Join(strings.Fields(cleanText(n.attrs["alt"], false)), " ")
+		label := "[image]"
+		if alt != "" {
+			label = "[image: " + alt + "]"
+		}
+		src := resolve(n.attrs["src"], r.options.Base)
+		if src == "" {
+			return r.style(styleDim, label, styleNoDim)
+		}
+		return r.style(styleDim, label, styleNoDim) + r.footnote(src)
+	default:
+		if blockTags[n.tag] {
+			// block content inside inline content runs on with it
+			return " " + strings.Join(joinBlocks(r.blocks(n, 0), false), " ") + " "
+		}
+		return r.inlineChildren(n)
+	}
+}
+
+// footnote numbers a link, giving a link seen before its earlier number.
+func (r *textRenderer) footnote(link string) string {
+	if r.options.NoFootnotes {
+		return ""
+	}
+	for i, seen := range r.links {
+		if seen == link {
+			return "[" + strconv.Itoa(i+1) + "]"
+		}
+	}
+	r.links = append(r.links, link)
+	return "[" + strconv.Itoa(len(r.links)) + "]"
+}
+
+// style wraps text in a style, when styling is on.
+func (r *textRenderer) style(start, text, end string) string {
+	if !r.options.Styled || strings.TrimSpace(text) == "" {
+		return text
+	}
+	return start + text + end
+}
+
+// wrap breaks text into lines of at most width characters, starting a line
+// at each line break. Styles open at the end of a line are ended there and
+// started again on the next, so every line can be shown on its own.
+func (r *textRenderer) wrap(text string, width int) []string {
+	var lines []string
+	var open []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		var line strings.Builder
+		lineWidth := 0
+		startLine := func() {
+			line.Reset()
+			for _, style := range open {
+				line.WriteString(style)
+			}
+			lineWidth = 0
+		}
+		endLine := func() {
+			if len(open) > 0 {
+				line.WriteString(styleReset)
+			}
+			lines = append(lines, line.String())
+		}
+
+		startLine()
+		for _, word := range strings.Fields(paragraph) {
+			wordWidth := visibleWidth(word)
+			if lineWidth > 0 && width > 0 && lineWidth+1+wordWidth > width {
+				endLine()
+				startLine()
+			}
+			if lineWidth > 0 {
+				line.WriteString(" ")
+				lineWidth++
+			}
+			// a word longer than a line is cut across lines
+			for width > 0 && lineWidth+wordWidth > width && wordWidth > width {
+				head, tail := cutVisible(word, width-lineWidth)
+				line.WriteString(head)
+				open = trackStyles(open, head)
+				endLine()
+				startLine()
+				word, wordWidth = tail, visibleWidth(tail)
+			}
+			line.WriteString(word)
+			open = trackStyles(open, word)
+			lineWidth += wordWidth
+		}
+		endLine()
+	}
+	return lines
+}
+
+// trackStyles follows the styles started and ended in text.
+func trackStyles(open []string, text string) []string {
+	for i := strings.IndexByte(text, 0x1b); i >= 0; i = strings.IndexByte(text, 0x1b) {
+		end := strings.IndexByte(text[i:], 'm')
+		if end < 0 {
+			break
+		}
+		code := text[i : i+end+1]
+		text = text[i+end+1:]
+		start, ending := styleEnds[code]
+		switch {
+		case code == styleReset:
+			open = nil
+		case ending:
+			for j := len(open) - 1; j >= 0; j-- {
+				// the end of bold ends dim too
+				if open[j] == start || (code == styleNoDim && open[j] == styleDim) {
+					open = append(open[:j:j], open[j+1:]...)
+					break
+				}
+			}
+		default:
+			open = append(open, code)
+		}
+	}
+	return open
+}
+
+// visibleWidth counts the terminal cells text takes up, leaving out escape
+// codes.
+func visibleWidth(text string) int {
+	width := 0
+	for i := 0; i < len(text); {
+		if text[i] == 0x1b {
+			end := strings.IndexByte(text[i:], 'm')
+			if end >= 0 {
+				i += end + 1
+				continue
+			}
+		}
+		r, size := utf8.DecodeRuneInString(text[i:])
+		width += runeWidth(r)
+		i += size
+	}
+	return width
+}
+
+// cutVisible splits text after width cells, keeping escape codes with the
+// characters they come after. The head always has a character, even one too
+// wide for the cells.
+func cutVisible(text string, width int) (string, string) {
+	width = max(width, 1)
+	taken := 0
+	for i := 0; i < len(text); {
+		if text[i] == 0x1b {
+			end := strings.IndexByte(text[i:], 'm')
+			if end >= 0 {
+				i += end + 1
+				continue
+			}
+		}
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if taken > 0 && taken+runeWidth(r) > width {
+			return text[:i], text[i:]
+		}
+		taken += runeWidth(r)
+		i += size
+	}
+	return text, ""
+}
+
+// wideRanges are the East Asian wide and fullwidth characters and emoji,
+// which terminals show in two cells.
+var wideRanges = []struct{ first, last rune }{
+	{0x1100, 0x115f},
+	{0x2e80, 0x303e},
+	{0x3041, 0x33ff},
+	{0x3400, 0x4dbf},
+	{0x4e00, 0x9fff},
+	{0xa000, 0xa4cf},
+	{0xac00, 0xd7a3},
+	{0xf900, 0xfaff},
+	{0xfe30, 0xfe4f},
+	{0xff00, 0xff60},
+	{0xffe0, 0xffe6},
+	{0x1f300, 0x1f64f},
+	{0x1f900, 0x1f9ff},
+	{0x20000, 0x3fffd},
+}
+
+// runeWidth is the number of cells a terminal shows r in.
+func runeWidth(r rune) int {
+	if r == 0x200d || unicode.In(r, unicode.Mn, unicode.Me) {
+		return 0
+	}
+	for _, wide := range wideRanges {
+		if r < wide.first {
+			break
+		}
+		if r <= wide.last {
+			return 2
+		}
+	}
+	return 1
+}
+
+// cleanText takes out the control characters a feed could use to send
+// escape codes to the terminal. Preformatted text keeps its line breaks and
+// has tabs expanded.
+func cleanText(text string, preformatted bool) string {
+	if preformatted {
+		text = strings.ReplaceAll(text, "\r\n", "\n")
+		text = strings.ReplaceAll(text, "\t", "    ")
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\n' || r == '\t':
+			return r
+		case r < 0x20 || (r >= 0x7f && r < 0xa0):
+			return -1
+		}
+		return r
+	}, text)
+}
+
+func prefixTextLines(lines []string, prefix, emptyPrefix string) []string {
+	prefixed := make([]string, len(lines))
+	for i, line := range lines {
+		if line == "" {
+			prefixed[i] = emptyPrefix
+		} else {
+			prefixed[i] = prefix + line
+		}
+	}
+	return prefixed
+}
+
+// indented is the width left for text indented by indent characters, never
+// so narrow that only a word or two fit.
+func indented(width, indent int) int {
+	if width == 0 {
+		return 0
+	}
+	return max(width-indent, 20)
+}
+
+func ruleWidth(width int) int {
+	if width == 0 {
+		return 40
+	}
+	return min(width, 40)
+}
@@ -0,0 +1,189 @@
+package markup
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestText(t *testing.T) {
+	base, err := url.Parse("https://example.com/posts/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		html    string
+		options TextOptions
+		want    []string
+	}{
+		{
+			name: "paragraphs",
+			html: "<p>one\n  two</p><p>three</p>",
+			want: []string{"one two", "", "three"},
+		},
+		{
+			name:    "wrapped",
+			html:    "<p>the quick brown fox jumps over the lazy dog</p>",
+			options: TextOptions{Width: 15},
+			want:    []string{"the quick brown", "fox jumps over", "the lazy dog"},
+		},
+		{
+			name: "line breaks",
+			html: "a<br>b",
+			want: []string{"a", "b"},
+		},
+		{
+			name: "headings",
+			html: "<h1>Title</h1><h2>Part</h2><h3>Small</h3>",
+			want: []string{"Title", "=====", "", "Part", "----", "", "Small"},
+		},
+		{
+			name:    "footnotes",
+			html:    `<p>see <a href="/a">this</a>, <a href="/a">that</a> and <img src="b.png" alt="B"></p>`,
+			options: TextOptions{Base: base},
+			want:    []string{"see this[1], that[1] and [image: B][2]", "", "[1] https://example.com/a", "[2] https://example.com/posts/b.png"},
+		},
+		{
+			name:    "no footnotes",
+			html:    `<a href="/a">this</a>`,
+			options: TextOptions{Base: base, NoFootnotes: true},
+			want:    []string{"this"},
+		},
+		{
+			name: "link showing its address",
+			html: `<a href="https://example.com/">https://example.com/</a>`,
+			want: []string{"https://example.com/"},
+		},
+		{
+			name: "javascript link",
+			html: `<a href="javascript:alert(1)">x</a>`,
+			want: []string{"x"},
+		},
+		{
+			name: "quote",
+			html: "<blockquote><p>a</p><p>b</p></blockquote>",
+			want: []string{"> a", "> ", "> b"},
+		},
+		{
+			name: "preformatted",
+			html: "<pre>if x {\n\treturn\n}</pre>",
+			want: []string{"    if x {", "        return", "    }"},
+		},
+		{
+			name: "lists",
+			html: `<ul><li>a</li><li>b</li></ul><ol start="9"><li>c</li><li>d</li></ol>`,
+			want: []string{"• a", "• b", "", "9. c", "10. d"},
+		},
+		{
+			name: "table",
+			html: "<table><tr><th>name</th><th>n</th></tr><tr><td>apples</td><td>3</td></tr></table>",
+			want: []string{"name    n", "apples  3"},
+		},
+		{
+			name: "dropped elements",
+			html: "a<script>b()</script><style>c{}</style>",
+			want: []string{"a"},
+		},
+		{
+			name: "escape codes from the feed",
+			html: "a\x1b[31mred\x1b[0m\x07",
+			want: []string{"a[31mred[0m"},
+		},
+		{
+			name:    "styled",
+			html:    "<p><b>bold</b> <em>it</em> <code>x</code></p>",
+			options: TextOptions{Styled: true},
+			want:    []string{styleBold + "bold" + styleNoBold + " " + styleItalic + "it" + styleNoItalic + " " + styleCode + "x" + styleNoCode},
+		},
+		{
+			name:    "styles carried across wrapped lines",
+			html:    "<b>one two three</b>",
+			options: TextOptions{Width: 8, Styled: true},
+			want:    []string{styleBold + "one two" + styleReset, styleBold + "three" + styleNoBold},
+		},
+		{
+			name:    "wide runes",
+			html:    "<p>日本語の文章を折り返す</p>",
+			options: TextOptions{Width: 8},
+			want:    []string{"日本語の", "文章を折", "り返す"},
+		},
+		{
+			name:    "wide runes between words",
+			html:    "<p>Go は 楽しい language</p>",
+			options: TextOptions{Width: 10},
+			want:    []string{"Go は", "楽しい", "language"},
+		},
+		{
+			name:    "emoji",
+			html:    "<p>🎉🎉🎉 done</p>",
+			options: TextOptions{Width: 6},
+			want:    []string{"🎉🎉🎉", "done"},
+		},
+		{
+			name:    "combining marks",
+			html:    "<p>cafe\u0301 cafe\u0301</p>",
+			options: TextOptions{Width: 9},
+			want:    []string{"cafe\u0301 cafe\u0301"},
+		},
+		{
+			name:    "wide table",
+			html:    "<table><tr><td>名前</td><td>n</td></tr><tr><td>abc</td><td>1</td></tr></table>",
+			options: TextOptions{Width: 40},
+			want:    []string{"名前  n", "abc   1"},
+		},
+		{
+			name:    "heading underline under wide runes",
+			html:    "<h1>見出し</h1>",
+			options: TextOptions{Width: 40},
+			want:    []string{"見出し", "======"},
+		},
+		{
+			name:    "long URL",
+			html:    "<p>see https://example.com/a/very/long/path/to/a/page.html now</p>",
+			options: TextOptions{Width: 20},
+			want:    []string{"see", "https://example.com/", "a/very/long/path/to/", "a/page.html now"},
+		},
+		{
+			name:    "long URL in a footnote",
+			html:    `<p><a href="https://example.com/a/very/long/path/to/a/page.html">page</a></p>`,
+			options: TextOptions{Width: 20},
+			want:    []string{"page[1]", "", "[1] https://example.com/a/very/long/path/to/a/page.html"},
+		},
+		{
+			name:    "long link styled across lines",
+			html:    `<a href="https://example.com/a/very/long/path">https://example.com/a/very/long/path</a>`,
+			options: TextOptions{Width: 20, Styled: true},
+			want: []string{
+				styleUnderline + "https://example.com/" + styleReset,
+				styleUnderline + "a/very/long/path" + styleNoUnder,
+			},
+		},
+		{
+			name:    "nested lists",
+			html:    "<ul><li>one<ol><li>two<ul><li>three</li></ul></li><li>four</li></ol></li><li>five</li></ul>",
+			options: TextOptions{Width: 40},
+			want:    []string{"• one", "  1. two", "     • three", "  2. four", "• five"},
+		},
+		{
+			name:    "nested list items wrapped",
+			html:    "<ul><li>outer<ul><li>an inner item long enough to wrap around</li></ul></li></ul>",
+			options: TextOptions{Width: 24},
+			want:    []string{"• outer", "  • an inner item long", "    enough to wrap", "    around"},
+		},
+		{
+			name:    "list item paragraphs",
+			html:    "<ol><li><p>first</p><p>second</p></li></ol>",
+			options: TextOptions{Width: 40},
+			want:    []string{"1. first", "", "   second"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Text(tt.html, tt.options)
+			if want := strings.Join(tt.want, "\n"); got != want {
+				t.Errorf("Text(%q) =\n%q\nwant\n%q", tt.html, got, want)
+			}
+		})
+	}
+}
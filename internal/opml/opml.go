@@ -0,0 +1,147 @@
+// Package opml reads and writes the OPML subscription lists feed readers
+// use to move feeds between each other.
+package opml
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+const Version = "2.0"
+
+type Document struct {
+	XMLName xml.Name `xml:"opml"`
+	Version string   `xml:"version,attr"`
+	Head    Head     `xml:"head"`
+	Body    Body     `xml:"body"`
+}
+
+type Body struct {
+	Outlines []Outline `xml:"outline"`
+}
+
+type Head struct {
+	Title       string `xml:"title,omitempty"`
+	DateCreated string `xml:"dateCreated,omitempty"`
+}
+
+type Outline struct {
+	Text     string    `xml:"text,attr"`
+	Title    string    `xml:"title,attr,omitempty"`
+	Type     string    `xml:"type,attr,omitempty"`
+	XMLURL   string    `xml:"xmlUrl,attr,omitempty"`
+	HTMLURL  string    `xml:"htmlUrl,attr,omitempty"`
+	Outlines []Outline `xml:"outline"`
+}
+
+// Feed is a subscription found in a document, with the titles of the
+// outlines it is nested in.
+type Feed struct {
+	Title   string
+	XMLURL  string
+	HTMLURL string
+	Folders []string
+}
+
+func Parse(r io.Reader) (*Document, error) {
+	doc := &Document{}
+	decoder := xml.NewDecoder(r)
+	// exports in the wild are often not quite XML
+	decoder.Strict = false
+	decoder.CharsetReader = charsetReader
+	err := decoder.Decode(doc)
+	if err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// Feeds flattens the outlines. Outlines without an xmlUrl are folders, any
+// other outline is a feed, even when it has children of its own.
+func (d *Document) Feeds() []Feed {
+	var feeds []Feed
+	var walk func(outlines []Outline, folders []string)
+	walk = func(outlines []Outline, folders []string) {
+		for _, outline := range outlines {
+			title := strings.TrimSpace(outline.Text)
+			if title == "" {
+				title = strings.TrimSpace(outline.Title)
+			}
+			if outline.XMLURL == "" {
+				walk(outline.Outlines, append(folders[:len(folders):len(folders)], title))
+				continue
+			}
+			feeds = append(feeds, Feed{
+				Title:   title,
+				XMLURL:  strings.TrimSpace(outline.XMLURL),
+				HTMLURL: strings.TrimSpace(outline.HTMLURL),
+				Folders: folders,
+			})
+			walk(outline.Outlines, folders)
+		}
+	}
+	walk(d.Body.Outlines, nil)
+	return feeds
+}
+
+// Add files the feed under its folders, creating the folder outlines it is
+// missing.
+func (d *Document) Add(feed Feed) {
+	outlines := &d.Body.Outlines
+	for _, folder := range feed.Folders {
+		found := -1
+		for i, outline := range *outlines {
+			if outline.XMLURL == "" && outline.Text == folder {
+				found = i
+				break
+			}
+		}
+		if found < 0 {
+			*outlines = append(*outlines, Outline{Text: folder, Title: folder})
+			found = len(*outlines) - 1
+		}
+		outlines = &(*outlines)[found].Outlines
+	}
+	*outlines = append(*outlines, Outline{
+		Text:    feed.Title,
+		Title:   feed.Title,
+		Type:    "rss",
+		XMLURL:  feed.XMLURL,
+		HTMLURL: feed.HTMLURL,
+	})
+}
+
+func (d *Document) Write(w io.Writer) error {
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	err = encoder.Encode(d)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+// charsetReader decodes Latin-1 and hands everything else over as UTF-8,
+// which is what exports labelled otherwise nearly always contain.
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(charset) {
+	case "iso-8859-1", "latin1", "latin-1":
+		raw, err := io.ReadAll(input)
+		if err != nil {
+			return nil, err
+		}
+		runes := make([]rune, len(raw))
+		for i, b := range raw {
+			runes[i] = rune(b)
+		}
+		return strings.NewReader(string(runes)), nil
+	default:
+		return input, nil
+	}
+}
@@ -0,0 +1,276 @@
+// Package postquery builds the post listings that have too many optional
+// filters for a static sqlc query, paging through a user's followed feeds
+// newest first, or by relevance for a full-text search.
+package postquery
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jdwalkerzhere/gator/internal/database"
+	"github.com/lib/pq"
+)
+
+type Filter struct {
+	UserID     uuid.UUID
+	Feed       string
+	Folder     string
+	Since      time.Time
+	Until      time.Time
+	UnreadOnly bool
+	Starred    bool
+	Author     string
+	Category   string
+	// Query adds the terms of a parsed query, and matches its text against
+	// the title, description and content.
+	Query
+	// ByRank orders Text matches by relevance instead of newest first.
+	ByRank bool
+	// Highlight wraps the matched words in the post snippets.
+	Highlight [2]string
+	// Languages are the text search configurations the followed feeds are
+	// indexed with; List looks them up when there's Text and none are set.
+	Languages []string
+	After     *Cursor
+	Offset    int32
+	Limit     int32
+}
+
+// Cursor marks the last post of a page; the next page starts right after it.
+type Cursor struct {
+	PublishedAt time.Time
+	ID          uuid.UUID
+}
+
+type Post struct {
+	ID          uuid.UUID
+	Title       string
+	Url         string
+	Description string
+	PublishedAt time.Time
+	FeedID      uuid.UUID
+	FeedName    string
+	FeedUrl     string
+	FeedSiteUrl string
+	// FeedLanguage is the text search configuration of the feed, if set.
+	FeedLanguage string
+	Guid         string
+	Author       string
+	Categories   []string
+	Content      string
+	Read         bool
+	Starred      bool
+	Rank         float64
+	Snippet      string
+}
+
+func (p Post) Cursor() Cursor {
+	return Cursor{PublishedAt: p.PublishedAt, ID: p.ID}
+}
+
+func (c Cursor) String() string {
+	raw := strconv.FormatInt(c.PublishedAt.UnixNano(), 10) + "/" + c.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func ParseCursor(value string) (Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("Invalid cursor [%s]", value)
+	}
+	nanos, id, ok := strings.Cut(string(raw), "/")
+	if !ok {
+		return Cursor{}, fmt.Errorf("Invalid cursor [%s]", value)
+	}
+	unixNano, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("Invalid cursor [%s]", value)
+	}
+	postID, err := uuid.Parse(id)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("Invalid cursor [%s]", value)
+	}
+	// published_at is a timestamp without time zone read back as UTC
+	return Cursor{PublishedAt: time.Unix(0, unixNano).UTC(), ID: postID}, nil
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// containsPattern matches value anywhere in a string with ILIKE ... ESCAPE
+// '\', taking any wildcards in it literally.
+func containsPattern(value string) string {
+	return "%" + likeEscaper.Replace(value) + "%"
+}
+
+type builder struct {
+	args  []any
+	where []string
+}
+
+func (b *builder) arg(value any) string {
+	b.args = append(b.args, value)
+	return "$" + strconv.Itoa(len(b.args))
+}
+
+func (b *builder) and(format string, values ...any) {
+	placeholders := make([]any, len(values))
+	for i, value := range values {
+		placeholders[i] = b.arg(value)
+	}
+	b.where = append(b.where, fmt.Sprintf(format, placeholders...))
+}
+
+func (f Filter) SQL() (string, []any) {
+	b := &builder{}
+	user := b.arg(f.UserID)
+	b.where = append(b.where, "posts.feed_id IN (SELECT feed_id FROM feed_follows WHERE user_id = "+user+")")
+	if !f.showsHidden() {
+		b.and("NOT COALESCE(post_states.hidden, false)")
+	}
+
+	if f.Feed != "" {
+		b.and(`(feeds.url = %s OR feeds.name ILIKE %s ESCAPE '\')`, f.Feed, containsPattern(f.Feed))
+	}
+	if f.Folder != "" {
+		b.and(`posts.feed_id IN (
+    SELECT feed_follows.feed_id
+    FROM folders
+    INNER JOIN folder_feeds ON folder_feeds.folder_id = folders.id
+    INNER JOIN feed_follows ON feed_follows.id = folder_feeds.feed_follow_id
+    WHERE folders.user_id = `+user+` AND folders.name = %s
+)`, f.Folder)
+	}
+	if !f.Since.IsZero() {
+		b.and("posts.published_at >= %s", f.Since)
+	}
+	if !f.Until.IsZero() {
+		b.and("posts.published_at < %s", f.Until)
+	}
+	if f.UnreadOnly {
+		b.and("NOT COALESCE(post_states.read, false)")
+	}
+	if f.Starred {
+		b.and("COALESCE(post_states.starred, false)")
+	}
+	if f.Author != "" {
+		b.and(`posts.author ILIKE %s ESCAPE '\'`, containsPattern(f.Author))
+	}
+	if f.Category != "" {
+		b.and("EXISTS (SELECT 1 FROM unnest(posts.categories) category WHERE lower(category) = lower(%s))", f.Category)
+	}
+	from := `FROM posts
+INNER JOIN feeds ON feeds.id = posts.feed_id
+LEFT JOIN post_states ON post_states.post_id = posts.id AND post_states.user_id = ` + user
+	rank, snippet := "0::real", "''"
+	if f.Text != "" {
+		// every post is parsed with its feed's language, so the text is parsed
+		// once per language; constant configurations keep the GIN index usable
+		search := f.searchQuery(b.arg(f.Text))
+		from += `
+INNER JOIN post_search ON post_search.post_id = posts.id`
+		b.where = append(b.where, "post_search.document @@ "+search)
+		rank = "ts_rank_cd(post_search.document, " + search + ")"
+		snippet = "ts_headline(post_search.language, post_search.text, " + search + ", " + b.arg(f.headlineOptions()) + ")"
+	}
+	for _, term := range f.Terms {
+		b.where = append(b.where, term.sql(b, user))
+	}
+	if f.After != nil {
+		b.and("(posts.published_at, posts.id) < (%s::timestamp, %s::uuid)", f.After.PublishedAt, f.After.ID)
+	}
+
+	order := "posts.published_at DESC, posts.id DESC"
+	if f.ByRank && f.Text != "" {
+		order = "search_rank DESC, " + order
+	}
+	query := `SELECT posts.id, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, feeds.name,
+    feeds.url, COALESCE(feeds.site_url, ''), COALESCE(feeds.language, ''), posts.guid, posts.author, posts.categories, posts.content, COALESCE(post_states.read, false), COALESCE(post_states.starred, false),
+    ` + rank + ` AS search_rank, ` + snippet + `
+` + from + `
+WHERE ` + strings.Join(b.where, "\nAND ") + `
+ORDER BY ` + order
+	if f.Limit > 0 {
+		query += "\nLIMIT " + b.arg(f.Limit)
+	}
+	if f.Offset > 0 {
+		query += "\nOFFSET " + b.arg(f.Offset)
+	}
+	return query, b.args
+}
+
+// searchQuery ORs together the text parsed with each of the languages.
+func (f Filter) searchQuery(text string) string {
+	languages := f.Languages
+	if len(languages) == 0 {
+		languages = []string{"simple"}
+	}
+	queries := make([]string, len(languages))
+	for i, language := range languages {
+		queries[i] = "websearch_to_tsquery(" + pq.QuoteLiteral(language) + "::regconfig, " + text + ")"
+	}
+	if len(queries) == 1 {
+		return queries[0]
+	}
+	return "(" + strings.Join(queries, " || ") + ")"
+}
+
+func (f Filter) headlineOptions() string {
+	options := "MaxFragments=2, MaxWords=24, MinWords=12"
+	if f.Highlight[0] != "" || f.Highlight[1] != "" {
+		options += fmt.Sprintf(`, StartSel="%s", StopSel="%s"`, f.Highlight[0], f.Highlight[1])
+	}
+	return options
+}
+
+func List(ctx context.Context, db database.DBTX, f Filter) ([]Post, error) {
+	if f.Text != "" && len(f.Languages) == 0 {
+		languages, err := database.New(db).ListSearchLanguages(ctx, f.UserID)
+		if err != nil {
+			return nil, err
+		}
+		f.Languages = languages
+	}
+	query, args := f.SQL()
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var posts []Post
+	for rows.Next() {
+		var p Post
+		if err := rows.Scan(
+			&p.ID,
+			&p.Title,
+			&p.Url,
+			&p.Description,
+			&p.PublishedAt,
+			&p.FeedID,
+			&p.FeedName,
+			&p.FeedUrl,
+			&p.FeedSiteUrl,
+			&p.FeedLanguage,
+			&p.Guid,
+			&p.Author,
+			pq.Array(&p.Categories),
+			&p.Content,
+			&p.Read,
+			&p.Starred,
+			&p.Rank,
+			&p.Snippet,
+		); err != nil {
+			return nil, err
+		}
+		posts = append(posts, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
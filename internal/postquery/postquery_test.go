@@ -0,0 +1,134 @@
+package postquery
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	cursor := Cursor{
+		PublishedAt: time.Date(2024, 5, 1, 12, 30, 0, 123456789, time.UTC),
+		ID:          uuid.MustParse("6f1c3f1e-8b2a-4c55-9d0e-2f4b8a7c1d3e"),
+	}
+	got, err := ParseCursor(cursor.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.PublishedAt.Equal(cursor.PublishedAt) || got.PublishedAt.Location() != time.UTC || got.ID != cursor.ID {
+		t.Errorf("ParseCursor(%s) = %+v, want %+v", cursor, got, cursor)
+	}
+}
+
+func TestParseCursorInvalid(t *testing.T) {
+	for _, value := range []string{
+		"",
+		"not base64!",
+		"MTIz",     // 123, no id
+		"YWJjL3h5", // abc/xy
+		"MTIzL3h5", // 123/xy
+	} {
+		if _, err := ParseCursor(value); err == nil {
+			t.Errorf("ParseCursor(%q) succeeded, want an error", value)
+		}
+	}
+}
+
+func TestContainsPattern(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "rsc", want: "%rsc%"},
+		{value: "100%", want: `%100\%%`},
+		{value: "a_b", want: `%a\_b%`},
+		{value: `C:\dir`, want: `%C:\\dir%`},
+	}
+	for _, tt := range tests {
+		if got := containsPattern(tt.value); got != tt.want {
+			t.Errorf("containsPattern(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestFilterSQL(t *testing.T) {
+	user := uuid.New()
+	after := Cursor{PublishedAt: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), ID: uuid.New()}
+	tests := []struct {
+		name     string
+		filter   Filter
+		contains []string
+		excludes []string
+		args     []any
+	}{
+		{
+			name:     "defaults",
+			filter:   Filter{UserID: user},
+			contains: []string{"user_id = $1", "NOT COALESCE(post_states.hidden, false)", "ORDER BY posts.published_at DESC, posts.id DESC"},
+			excludes: []string{"LIMIT", "OFFSET", "post_search"},
+			args:     []any{user},
+		},
+		{
+			name:     "feed and author",
+			filter:   Filter{UserID: user, Feed: "Go_Blog", Author: "50%"},
+			contains: []string{`(feeds.url = $2 OR feeds.name ILIKE $3 ESCAPE '\')`, `posts.author ILIKE $4 ESCAPE '\'`},
+			args:     []any{user, "Go_Blog", `%Go\_Blog%`, `%50\%%`},
+		},
+		{
+			name:     "query terms",
+			filter:   Filter{UserID: user, Query: Query{Terms: []Term{{Field: "feed", Value: "Go_Blog"}, {Field: "title", Value: "x", Negate: true}}}},
+			contains: []string{`(feeds.url = $2 OR feeds.name ILIKE $3 ESCAPE '\')`, `NOT (posts.title ILIKE $4 ESCAPE '\')`},
+			args:     []any{user, "Go_Blog", `%Go\_Blog%`, "%x%"},
+		},
+		{
+			name:     "hidden shown",
+			filter:   Filter{UserID: user, Query: Query{Terms: []Term{{Field: "is", Value: "hidden"}}}},
+			contains: []string{"AND COALESCE(post_states.hidden, false)"},
+			excludes: []string{"NOT COALESCE(post_states.hidden, false)"},
+		},
+		{
+			name:     "text search ranked",
+			filter:   Filter{UserID: user, Query: Query{Text: "generics"}, ByRank: true, Languages: []string{"english", "german"}},
+			contains: []string{"INNER JOIN post_search", "websearch_to_tsquery('english'::regconfig, $2) || websearch_to_tsquery('german'::regconfig, $2)", "ORDER BY search_rank DESC"},
+		},
+		{
+			name:     "rank without text",
+			filter:   Filter{UserID: user, ByRank: true},
+			excludes: []string{"search_rank DESC"},
+		},
+		{
+			name:     "page after a cursor",
+			filter:   Filter{UserID: user, After: &after, Limit: 10, Offset: 20},
+			contains: []string{"(posts.published_at, posts.id) < ($2::timestamp, $3::uuid)", "LIMIT $4", "OFFSET $5"},
+			args:     []any{user, after.PublishedAt, after.ID, int32(10), int32(20)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, args := tt.filter.SQL()
+			for _, want := range tt.contains {
+				if !strings.Contains(query, want) {
+					t.Errorf("query doesn't contain %q:\n%s", want, query)
+				}
+			}
+			for _, unwanted := range tt.excludes {
+				if strings.Contains(query, unwanted) {
+					t.Errorf("query contains %q:\n%s", unwanted, query)
+				}
+			}
+			if tt.args == nil {
+				return
+			}
+			if len(args) != len(tt.args) {
+				t.Fatalf("args = %v, want %v", args, tt.args)
+			}
+			for i := range args {
+				if args[i] != tt.args[i] {
+					t.Errorf("arg $%d = %v, want %v", i+1, args[i], tt.args[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,257 @@
+package postquery
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Query is a parsed search such as
+//
+//	feed:golang author:rsc "generics" -draft after:2024-01-01 is:unread
+//
+// Field terms narrow the posts down, everything else is full-text search.
+type Query struct {
+	// Text holds the words, "phrases", -exclusions and ors, in the form
+	// websearch_to_tsquery expects.
+	Text  string
+	Terms []Term
+}
+
+type Term struct {
+	Field  string
+	Value  string
+	Negate bool
+	// Time is the parsed value of the after: and before: fields.
+	Time time.Time
+}
+
+// fields lists the fields a query understands and what they match. Any other
+// word with a colon is searched for as text.
+var fields = map[string]string{
+	"feed":     "feed URL or part of its name",
+	"folder":   "one of your folders",
+	"author":   "part of the author",
+	"category": "a category",
+	"title":    "part of the title",
+	"after":    "published on or after a date or age",
+	"before":   "published before a date or age",
+	"is":       "read, unread, starred, queued or hidden",
+}
+
+var states = map[string]bool{
+	"read":    true,
+	"unread":  true,
+	"starred": true,
+	"queued":  true,
+	"hidden":  true,
+}
+
+func Parse(query string) (Query, error) {
+	q := Query{}
+	var text []string
+	rest := strings.TrimSpace(query)
+	for rest != "" {
+		negate := false
+		if strings.HasPrefix(rest, "-") && len(rest) > 1 && !unicode.IsSpace(rune(rest[1])) {
+			negate, rest = true, rest[1:]
+		}
+
+		var token string
+		var err error
+		field, value, isField := cutField(rest)
+		if isField {
+			value, rest, err = readValue(value)
+			if err != nil {
+				return q, err
+			}
+			term, err := newTerm(field, value, negate)
+			if err != nil {
+				return q, err
+			}
+			q.Terms = append(q.Terms, term)
+			rest = strings.TrimSpace(rest)
+			continue
+		}
+
+		token, rest, err = readValue(rest)
+		if err != nil {
+			return q, err
+		}
+		if strings.ContainsFunc(token, unicode.IsSpace) {
+			token = `"` + token + `"`
+		}
+		if negate {
+			token = "-" + token
+		}
+		text = append(text, token)
+		rest = strings.TrimSpace(rest)
+	}
+	q.Text = strings.Join(text, " ")
+	return q, nil
+}
+
+// cutField splits "field:value..." when the text starts with a field name.
+func cutField(text string) (field, rest string, ok bool) {
+	end := strings.IndexFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	if end <= 0 || text[end] != ':' {
+		return "", text, false
+	}
+	field = strings.ToLower(text[:end])
+	if _, known := fields[field]; !known {
+		return "", text, false
+	}
+	return field, text[end+1:], true
+}
+
+// readValue reads a "quoted" or bare value and returns what follows it.
+func readValue(text string) (value, rest string, err error) {
+	if strings.HasPrefix(text, `"`) {
+		end := strings.Index(text[1:], `"`)
+		if end < 0 {
+			return "", "", fmt.Errorf("Unterminated quote in query at [%s]", text)
+		}
+		return text[1 : end+1], text[end+2:], nil
+	}
+	end := strings.IndexFunc(text, unicode.IsSpace)
+	if end < 0 {
+		return text, "", nil
+	}
+	return text[:end], text[end:], nil
+}
+
+func newTerm(field, value string, negate bool) (Term, error) {
+	term := Term{Field: field, Value: value, Negate: negate}
+	if value == "" {
+		return term, fmt.Errorf("Missing value for [%s:] in query", field)
+	}
+	switch field {
+	case "after", "before":
+		t, err := ParseCutoff(value)
+		if err != nil {
+			return term, err
+		}
+		term.Time = t
+	case "is":
+		term.Value = strings.ToLower(value)
+		if !states[term.Value] {
+			return term, fmt.Errorf("Unknown state [is:%s], use is:read, is:unread, is:starred, is:queued or is:hidden", value)
+		}
+	}
+	return term, nil
+}
+
+// Fields describes the fields a query understands, one "name: meaning" per line.
+func Fields() []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	lines := make([]string, len(names))
+	for i, name := range names {
+		lines[i] = name + ": " + fields[name]
+	}
+	return lines
+}
+
+// JoinArgs turns command line arguments back into a query, quoting the ones
+// the shell unquoted so phrases and field values with spaces survive.
+func JoinArgs(args []string) string {
+	parts := make([]string, 0, len(args))
+	for _, arg := range args {
+		if !strings.ContainsFunc(arg, unicode.IsSpace) || strings.Contains(arg, `"`) {
+			parts = append(parts, arg)
+			continue
+		}
+		prefix, value := "", arg
+		if strings.HasPrefix(value, "-") {
+			prefix, value = "-", value[1:]
+		}
+		if field, fieldValue, ok := cutField(value); ok {
+			prefix, value = prefix+field+":", fieldValue
+		}
+		parts = append(parts, prefix+`"`+value+`"`)
+	}
+	return strings.Join(parts, " ")
+}
+
+// ParseCutoff accepts a date (2006-01-02), an RFC 3339 timestamp or an age
+// such as 36h or 30d, and returns the point in time it refers to.
+func ParseCutoff(value string) (time.Time, error) {
+	if cutoff, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		return cutoff, nil
+	}
+	if cutoff, err := time.Parse(time.RFC3339, value); err == nil {
+		return cutoff, nil
+	}
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil {
+			return time.Now().AddDate(0, 0, -n), nil
+		}
+	}
+	age, err := time.ParseDuration(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Error parsing [%s], please provide a date (YYYY-MM-DD) or an age such as 12h or 30d\n", value)
+	}
+	return time.Now().Add(-age), nil
+}
+
+// showsHidden reports whether the query asks for posts hidden by a rule.
+func (q Query) showsHidden() bool {
+	for _, term := range q.Terms {
+		if term.Field == "is" && term.Value == "hidden" && !term.Negate {
+			return true
+		}
+	}
+	return false
+}
+
+// sql compiles the term into a condition on the posts of the user.
+func (t Term) sql(b *builder, user string) string {
+	var clause string
+	switch t.Field {
+	case "feed":
+		clause = fmt.Sprintf(`(feeds.url = %s OR feeds.name ILIKE %s ESCAPE '\')`, b.arg(t.Value), b.arg(containsPattern(t.Value)))
+	case "folder":
+		clause = `posts.feed_id IN (
+    SELECT feed_follows.feed_id
+    FROM folders
+    INNER JOIN folder_feeds ON folder_feeds.folder_id = folders.id
+    INNER JOIN feed_follows ON feed_follows.id = folder_feeds.feed_follow_id
+    WHERE folders.user_id = ` + user + ` AND folders.name = ` + b.arg(t.Value) + `
+)`
+	case "author":
+		clause = "posts.author ILIKE " + b.arg(containsPattern(t.Value)) + ` ESCAPE '\'`
+	case "category":
+		clause = "EXISTS (SELECT 1 FROM unnest(posts.categories) category WHERE lower(category) = lower(" + b.arg(t.Value) + "))"
+	case "title":
+		clause = "posts.title ILIKE " + b.arg(containsPattern(t.Value)) + ` ESCAPE '\'`
+	case "after":
+		clause = "posts.published_at >= " + b.arg(t.Time)
+	case "before":
+		clause = "posts.published_at < " + b.arg(t.Time)
+	case "is":
+		switch t.Value {
+		case "read":
+			clause = "COALESCE(post_states.read, false)"
+		case "unread":
+			clause = "NOT COALESCE(post_states.read, false)"
+		case "starred":
+			clause = "COALESCE(post_states.starred, false)"
+		case "hidden":
+			clause = "COALESCE(post_states.hidden, false)"
+		case "queued":
+			clause = "EXISTS (SELECT 1 FROM read_later WHERE read_later.user_id = " + user + " AND read_later.post_id = posts.id)"
+		}
+	}
+	if t.Negate {
+		return "NOT (" + clause + ")"
+	}
+	return clause
+}
@@ -0,0 +1,158 @@
+package postquery
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		text    string
+		terms   []Term
+		wantErr string
+	}{
+		{name: "empty", query: "  "},
+		{name: "words", query: "type  parameters", text: "type parameters"},
+		{name: "phrase", query: `"type parameters" go`, text: `"type parameters" go`},
+		{name: "exclusion", query: "generics -draft", text: "generics -draft"},
+		{name: "lone dash", query: "a - b", text: "a - b"},
+		{name: "or", query: "rust or go", text: "rust or go"},
+		{
+			name:  "fields",
+			query: `feed:golang author:"Russ Cox" generics is:Unread`,
+			text:  "generics",
+			terms: []Term{{Field: "feed", Value: "golang"}, {Field: "author", Value: "Russ Cox"}, {Field: "is", Value: "unread"}},
+		},
+		{name: "negated field", query: "-feed:news", terms: []Term{{Field: "feed", Value: "news", Negate: true}}},
+		{name: "field names ignore case", query: "Title:go", terms: []Term{{Field: "title", Value: "go"}}},
+		{name: "unknown field is text", query: "http://example.com lang:go", text: "http://example.com lang:go"},
+		{name: "unterminated quote", query: `"type parameters`, wantErr: "Unterminated quote"},
+		{name: "missing value", query: "author: rsc", wantErr: "Missing value for [author:]"},
+		{name: "unknown state", query: "is:new", wantErr: "Unknown state [is:new]"},
+		{name: "bad date", query: "after:yesterday", wantErr: "Error parsing [yesterday]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := Parse(tt.query)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Parse(%q) error = %v, want %q", tt.query, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if q.Text != tt.text {
+				t.Errorf("text = %q, want %q", q.Text, tt.text)
+			}
+			if len(q.Terms) != len(tt.terms) {
+				t.Fatalf("terms = %+v, want %+v", q.Terms, tt.terms)
+			}
+			for i, term := range q.Terms {
+				if term != tt.terms[i] {
+					t.Errorf("term %d = %+v, want %+v", i, term, tt.terms[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseDates(t *testing.T) {
+	q, err := Parse("after:2024-01-02 before:7d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(q.Terms) != 2 {
+		t.Fatalf("terms = %+v", q.Terms)
+	}
+	if want := time.Date(2024, 1, 2, 0, 0, 0, 0, time.Local); !q.Terms[0].Time.Equal(want) {
+		t.Errorf("after = %s, want %s", q.Terms[0].Time, want)
+	}
+	if age := time.Since(q.Terms[1].Time); age < 7*24*time.Hour-time.Hour || age > 7*24*time.Hour+time.Hour {
+		t.Errorf("before:7d is %s ago", age)
+	}
+}
+
+func TestParseCutoff(t *testing.T) {
+	tests := []struct {
+		value string
+		age   time.Duration
+		at    time.Time
+	}{
+		{value: "2024-03-04", at: time.Date(2024, 3, 4, 0, 0, 0, 0, time.Local)},
+		{value: "2024-03-04T05:06:07Z", at: time.Date(2024, 3, 4, 5, 6, 7, 0, time.UTC)},
+		{value: "36h", age: 36 * time.Hour},
+		{value: "30d", age: 30 * 24 * time.Hour},
+		{value: "90m", age: 90 * time.Minute},
+	}
+	for _, tt := range tests {
+		got, err := ParseCutoff(tt.value)
+		if err != nil {
+			t.Errorf("ParseCutoff(%q): %v", tt.value, err)
+			continue
+		}
+		if !tt.at.IsZero() {
+			if !got.Equal(tt.at) {
+				t.Errorf("ParseCutoff(%q) = %s, want %s", tt.value, got, tt.at)
+			}
+			continue
+		}
+		// days are calendar days, which a DST change can make an hour off
+		if age := time.Since(got); age < tt.age-time.Hour || age > tt.age+time.Hour {
+			t.Errorf("ParseCutoff(%q) is %s ago, want %s", tt.value, age, tt.age)
+		}
+	}
+
+	for _, value := range []string{"", "soon", "2024-13-01", "d"} {
+		if _, err := ParseCutoff(value); err == nil {
+			t.Errorf("ParseCutoff(%q) succeeded, want an error", value)
+		}
+	}
+}
+
+func TestJoinArgs(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{args: []string{"generics", "-draft"}, want: "generics -draft"},
+		{args: []string{"type parameters"}, want: `"type parameters"`},
+		{args: []string{"-hacker news"}, want: `-"hacker news"`},
+		{args: []string{"author:Russ Cox", "-feed:Go Blog"}, want: `author:"Russ Cox" -feed:"Go Blog"`},
+		{args: []string{`already "quoted" text`}, want: `already "quoted" text`},
+		{args: []string{"lang:go rust"}, want: `"lang:go rust"`},
+	}
+	for _, tt := range tests {
+		if got := JoinArgs(tt.args); got != tt.want {
+			t.Errorf("JoinArgs(%q) = %s, want %s", tt.args, got, tt.want)
+		}
+	}
+
+	// what the shell split comes back as the same query
+	q, err := Parse(JoinArgs([]string{"author:Russ Cox", "type parameters"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Text != `"type parameters"` || len(q.Terms) != 1 || q.Terms[0].Value != "Russ Cox" {
+		t.Errorf("round trip = %+v", q)
+	}
+}
+
+func TestFields(t *testing.T) {
+	lines := Fields()
+	if len(lines) != len(fields) {
+		t.Fatalf("got %d fields, want %d", len(lines), len(fields))
+	}
+	if lines[0] != "after: published on or after a date or age" {
+		t.Errorf("first field = %q, want them sorted by name", lines[0])
+	}
+	for i := 1; i < len(lines); i++ {
+		if lines[i-1] > lines[i] {
+			t.Errorf("%q comes before %q", lines[i-1], lines[i])
+		}
+	}
+}
@@ -0,0 +1,266 @@
+// Package syndication writes lists of posts back out as Atom 1.0 or RSS 2.0
+// documents, keeping track of the feed each item was originally published in.
+package syndication
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"time"
+)
+
+const (
+	atomNamespace    = "http://www.w3.org/2005/Atom"
+	contentNamespace = "http://purl.org/rss/1.0/modules/content/"
+	dcNamespace      = "http://purl.org/dc/elements/1.1/"
+	generator        = "gator"
+)
+
+type Feed struct {
+	// ID is a permanent IRI for the document, Atom readers use it to tell
+	// feeds apart.
+	ID          string
+	Title       string
+	Description string
+	// Link is where the document itself is published, if anywhere.
+	Link string
+	// SiteURL is the page the items come from, when they all come from one.
+	SiteURL string
+	Author  string
+	Updated time.Time
+	Items   []Item
+}
+
+type Item struct {
+	// ID is the item's original id when it has one, so readers following
+	// both the source and this feed see the same entry.
+	ID          string
+	IsPermaLink bool
+	Title       string
+	Link        string
+	Summary     string
+	Content     string
+	Author      string
+	Categories  []string
+	Published   time.Time
+	Source      Source
+}
+
+// Source is the feed an item was aggregated from.
+type Source struct {
+	Title   string
+	URL     string
+	SiteURL string
+}
+
+type atomFeed struct {
+	XMLName   xml.Name    `xml:"feed"`
+	Namespace string      `xml:"xmlns,attr"`
+	ID        string      `xml:"id"`
+	Title     atomText    `xml:"title"`
+	Subtitle  *atomText   `xml:"subtitle,omitempty"`
+	Updated   string      `xml:"updated"`
+	Links     []atomLink  `xml:"link"`
+	Author    atomPerson  `xml:"author"`
+	Generator string      `xml:"generator"`
+	Entries   []atomEntry `xml:"entry"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr,omitempty"`
+	Text string `xml:",chardata"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      atomText       `xml:"title"`
+	Links      []atomLink     `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Author     *atomPerson    `xml:"author,omitempty"`
+	Categories []atomCategory `xml:"category"`
+	Summary    *atomText      `xml:"summary,omitempty"`
+	Content    *atomText      `xml:"content,omitempty"`
+	Source     atomSource     `xml:"source"`
+}
+
+type atomSource struct {
+	ID    string     `xml:"id"`
+	Title atomText   `xml:"title"`
+	Links []atomLink `xml:"link"`
+}
+
+// WriteAtom writes the feed as Atom 1.0. Entries carry an atom:source so the
+// feed they came from is kept, as RFC 4287 asks of aggregated entries.
+func WriteAtom(w io.Writer, feed Feed) error {
+	doc := atomFeed{
+		Namespace: atomNamespace,
+		ID:        feed.ID,
+		Title:     atomText{Text: feed.Title},
+		Updated:   feed.Updated.UTC().Format(time.RFC3339),
+		Author:    atomPerson{Name: feed.Author},
+		Generator: generator,
+	}
+	if feed.Description != "" {
+		doc.Subtitle = &atomText{Text: feed.Description}
+	}
+	if feed.Link != "" {
+		doc.Links = append(doc.Links, atomLink{Rel: "self", Type: "application/atom+xml", Href: feed.Link})
+	}
+	if feed.SiteURL != "" {
+		doc.Links = append(doc.Links, atomLink{Rel: "alternate", Type: "text/html", Href: feed.SiteURL})
+	}
+
+	for _, item := range feed.Items {
+		published := item.Published.UTC().Format(time.RFC3339)
+		entry := atomEntry{
+			ID:        item.ID,
+			Title:     atomText{Text: item.Title},
+			Published: published,
+			Updated:   published,
+			Source: atomSource{
+				ID:    item.Source.URL,
+				Title: atomText{Text: item.Source.Title},
+				Links: []atomLink{{Rel: "self", Href: item.Source.URL}},
+			},
+		}
+		if item.Link != "" {
+			entry.Links = append(entry.Links, atomLink{Rel: "alternate", Type: "text/html", Href: item.Link})
+		}
+		if item.Source.SiteURL != "" {
+			entry.Source.Links = append(entry.Source.Links, atomLink{Rel: "alternate", Href: item.Source.SiteURL})
+		}
+		if item.Author != "" {
+			entry.Author = &atomPerson{Name: item.Author}
+		}
+		for _, category := range item.Categories {
+			entry.Categories = append(entry.Categories, atomCategory{Term: category})
+		}
+		if item.Summary != "" {
+			entry.Summary = &atomText{Type: "html", Text: item.Summary}
+		}
+		if item.Content != "" {
+			entry.Content = &atomText{Type: "html", Text: item.Content}
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+	return write(w, doc)
+}
+
+type rssDocument struct {
+	XMLName          xml.Name   `xml:"rss"`
+	Version          string     `xml:"version,attr"`
+	AtomNamespace    string     `xml:"xmlns:atom,attr"`
+	ContentNamespace string     `xml:"xmlns:content,attr"`
+	DCNamespace      string     `xml:"xmlns:dc,attr"`
+	Channel          rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Generator     string    `xml:"generator"`
+	SelfLink      *atomLink `xml:"atom:link,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string    `xml:"title"`
+	Link        string    `xml:"link,omitempty"`
+	Description string    `xml:"description,omitempty"`
+	Content     string    `xml:"content:encoded,omitempty"`
+	Creator     string    `xml:"dc:creator,omitempty"`
+	Categories  []string  `xml:"category"`
+	GUID        rssGUID   `xml:"guid"`
+	PubDate     string    `xml:"pubDate"`
+	Source      rssSource `xml:"source"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssSource struct {
+	URL   string `xml:"url,attr"`
+	Title string `xml:",chardata"`
+}
+
+// ErrNoChannelLink is returned by WriteRSS for a feed with neither a Link
+// nor a SiteURL, as every RSS channel needs a link.
+var ErrNoChannelLink = errors.New("RSS channel needs a link")
+
+// WriteRSS writes the feed as RSS 2.0. RSS authors have to be email
+// addresses, so item authors go in dc:creator instead. The channel links to
+// the site the items come from, or else to the feed itself.
+func WriteRSS(w io.Writer, feed Feed) error {
+	channelLink := feed.SiteURL
+	if channelLink == "" {
+		channelLink = feed.Link
+	}
+	if channelLink == "" {
+		return ErrNoChannelLink
+	}
+	doc := rssDocument{
+		Version:          "2.0",
+		AtomNamespace:    atomNamespace,
+		ContentNamespace: contentNamespace,
+		DCNamespace:      dcNamespace,
+		Channel: rssChannel{
+			Title:         feed.Title,
+			Link:          channelLink,
+			Description:   feed.Description,
+			LastBuildDate: feed.Updated.Format(time.RFC1123Z),
+			Generator:     generator,
+		},
+	}
+	if feed.Link != "" {
+		doc.Channel.SelfLink = &atomLink{Rel: "self", Type: "application/rss+xml", Href: feed.Link}
+	}
+
+	for _, item := range feed.Items {
+		doc.Channel.Items = append(doc.Channel.Items, rssItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			Description: item.Summary,
+			Content:     item.Content,
+			Creator:     item.Author,
+			Categories:  item.Categories,
+			GUID:        rssGUID{IsPermaLink: item.IsPermaLink, Value: item.ID},
+			PubDate:     item.Published.Format(time.RFC1123Z),
+			Source:      rssSource{URL: item.Source.URL, Title: item.Source.Title},
+		})
+	}
+	return write(w, doc)
+}
+
+func write(w io.Writer, doc any) error {
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	err = encoder.Encode(doc)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
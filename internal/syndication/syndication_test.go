@@ -0,0 +1,217 @@
+package syndication
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testFeed() Feed {
+	published := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	return Feed{
+		ID:          "urn:uuid:1",
+		Title:       "Timeline",
+		Description: "Posts & more",
+		Link:        "https://example.com/timeline.xml",
+		Author:      "ada",
+		Updated:     published,
+		Items: []Item{
+			{
+				ID:          "https://blog.example.com/1",
+				IsPermaLink: true,
+				Title:       "First <post>",
+				Link:        "https://blog.example.com/1",
+				Summary:     "<p>summary</p>",
+				Content:     "<p>content &amp; more</p>",
+				Author:      "Grace",
+				Categories:  []string{"go", "feeds"},
+				Published:   published,
+				Source:      Source{Title: "Blog", URL: "https://blog.example.com/feed.xml", SiteURL: "https://blog.example.com/"},
+			},
+			{
+				ID:        "tag:example.com,2024:2",
+				Title:     "Second",
+				Published: published.Add(-time.Hour),
+				Source:    Source{Title: "Other", URL: "https://other.example.com/rss"},
+			},
+		},
+	}
+}
+
+type atomLinkDoc struct {
+	Rel  string `xml:"rel,attr"`
+	Href string `xml:"href,attr"`
+}
+
+type atomDoc struct {
+	XMLName xml.Name      `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string        `xml:"id"`
+	Title   string        `xml:"title"`
+	Updated string        `xml:"updated"`
+	Links   []atomLinkDoc `xml:"link"`
+	Entries []struct {
+		ID         string        `xml:"id"`
+		Title      string        `xml:"title"`
+		Links      []atomLinkDoc `xml:"link"`
+		Published  string        `xml:"published"`
+		Author     string        `xml:"author>name"`
+		Categories []struct {
+			Term string `xml:"term,attr"`
+		} `xml:"category"`
+		Summary string `xml:"summary"`
+		Content struct {
+			Type string `xml:"type,attr"`
+			Text string `xml:",chardata"`
+		} `xml:"content"`
+		Source struct {
+			ID    string        `xml:"id"`
+			Title string        `xml:"title"`
+			Links []atomLinkDoc `xml:"link"`
+		} `xml:"source"`
+	} `xml:"entry"`
+}
+
+func TestWriteAtom(t *testing.T) {
+	var out bytes.Buffer
+	if err := WriteAtom(&out, testFeed()); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out.String(), xml.Header) {
+		t.Errorf("document doesn't start with the XML header:\n%s", out.String())
+	}
+	doc := atomDoc{}
+	if err := xml.Unmarshal(out.Bytes(), &doc); err != nil {
+		t.Fatalf("reading the document back: %v\n%s", err, out.String())
+	}
+
+	if doc.ID != "urn:uuid:1" || doc.Title != "Timeline" || doc.Updated != "2024-05-01T12:30:00Z" {
+		t.Errorf("feed = %q %q %q", doc.ID, doc.Title, doc.Updated)
+	}
+	if len(doc.Links) != 1 || doc.Links[0].Rel != "self" || doc.Links[0].Href != "https://example.com/timeline.xml" {
+		t.Errorf("feed links = %v, want the self link", doc.Links)
+	}
+	if len(doc.Entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(doc.Entries))
+	}
+
+	first := doc.Entries[0]
+	if first.ID != "https://blog.example.com/1" || first.Title != "First <post>" || first.Author != "Grace" {
+		t.Errorf("entry = %q %q by %q", first.ID, first.Title, first.Author)
+	}
+	if first.Content.Type != "html" || first.Content.Text != "<p>content &amp; more</p>" {
+		t.Errorf("content = %q of type %q, want the HTML kept", first.Content.Text, first.Content.Type)
+	}
+	if len(first.Categories) != 2 || first.Categories[0].Term != "go" {
+		t.Errorf("categories = %v", first.Categories)
+	}
+	if first.Source.ID != "https://blog.example.com/feed.xml" || first.Source.Title != "Blog" || len(first.Source.Links) != 2 {
+		t.Errorf("source = %+v", first.Source)
+	}
+
+	second := doc.Entries[1]
+	if len(second.Links) != 0 || second.Author != "" || second.Summary != "" {
+		t.Errorf("entry without a link, author or summary got %v %q %q", second.Links, second.Author, second.Summary)
+	}
+	if second.Published != "2024-05-01T11:30:00Z" {
+		t.Errorf("published = %q", second.Published)
+	}
+}
+
+type rssDoc struct {
+	Version string `xml:"version,attr"`
+	Channel struct {
+		Title string `xml:"title"`
+		// the channel's own link, then its atom:link
+		Links       []string `xml:"link"`
+		Description string   `xml:"description"`
+		Items       []struct {
+			Title   string `xml:"title"`
+			Link    string `xml:"link"`
+			Content string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+			Creator string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+			GUID    struct {
+				IsPermaLink bool   `xml:"isPermaLink,attr"`
+				Value       string `xml:",chardata"`
+			} `xml:"guid"`
+			PubDate string `xml:"pubDate"`
+			Source  struct {
+				URL   string `xml:"url,attr"`
+				Title string `xml:",chardata"`
+			} `xml:"source"`
+		} `xml:"item"`
+	} `xml:"channel"`
+}
+
+func TestWriteRSS(t *testing.T) {
+	var out bytes.Buffer
+	if err := WriteRSS(&out, testFeed()); err != nil {
+		t.Fatal(err)
+	}
+	doc := rssDoc{}
+	if err := xml.Unmarshal(out.Bytes(), &doc); err != nil {
+		t.Fatalf("reading the document back: %v\n%s", err, out.String())
+	}
+
+	if doc.Version != "2.0" || doc.Channel.Title != "Timeline" || doc.Channel.Description != "Posts & more" {
+		t.Errorf("channel = %s %q %q", doc.Version, doc.Channel.Title, doc.Channel.Description)
+	}
+	if len(doc.Channel.Items) != 2 {
+		t.Fatalf("got %d items, want 2", len(doc.Channel.Items))
+	}
+	first := doc.Channel.Items[0]
+	if first.Creator != "Grace" || first.Content != "<p>content &amp; more</p>" {
+		t.Errorf("item by %q with content %q", first.Creator, first.Content)
+	}
+	if !first.GUID.IsPermaLink || first.GUID.Value != "https://blog.example.com/1" {
+		t.Errorf("guid = %+v", first.GUID)
+	}
+	if first.PubDate != "Wed, 01 May 2024 12:30:00 +0000" {
+		t.Errorf("pubDate = %q", first.PubDate)
+	}
+	if first.Source.URL != "https://blog.example.com/feed.xml" || first.Source.Title != "Blog" {
+		t.Errorf("source = %+v", first.Source)
+	}
+	second := doc.Channel.Items[1]
+	if second.GUID.IsPermaLink || second.GUID.Value != "tag:example.com,2024:2" || second.Link != "" {
+		t.Errorf("item without a link got guid %+v and link %q", second.GUID, second.Link)
+	}
+}
+
+func TestWriteRSSChannelLink(t *testing.T) {
+	tests := []struct {
+		name    string
+		link    string
+		siteURL string
+		want    string
+		wantErr error
+	}{
+		{name: "site", link: "https://example.com/feed.rss", siteURL: "https://blog.example.com/", want: "https://blog.example.com/"},
+		{name: "feed itself", link: "https://example.com/feed.rss", want: "https://example.com/feed.rss"},
+		{name: "site only", siteURL: "https://blog.example.com/", want: "https://blog.example.com/"},
+		{name: "neither", wantErr: ErrNoChannelLink},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			feed := testFeed()
+			feed.Link, feed.SiteURL = tt.link, tt.siteURL
+			var out bytes.Buffer
+			err := WriteRSS(&out, feed)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("WriteRSS error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			doc := rssDoc{}
+			if err := xml.Unmarshal(out.Bytes(), &doc); err != nil {
+				t.Fatal(err)
+			}
+			if len(doc.Channel.Links) == 0 || doc.Channel.Links[0] != tt.want {
+				t.Errorf("channel links = %q, want %q first", doc.Channel.Links, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,228 @@
+// Package term drives a full-screen terminal: raw keyboard input, the
+// alternate screen and its size, with just enough escape codes to draw it.
+package term
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+var ErrUnsupported = errors.New("Full-screen terminals aren't supported on this system")
+
+// Escape codes for the few styles the screen uses.
+const (
+	Reset   = "\x1b[0m"
+	Bold    = "\x1b[1m"
+	Dim     = "\x1b[2m"
+	Reverse = "\x1b[7m"
+)
+
+type Terminal struct {
+	in    *os.File
+	out   *os.File
+	saved *termios
+}
+
+// Open switches the terminal on stdin and stdout to raw input and the
+// alternate screen. Close puts it back the way it was.
+func Open() (*Terminal, error) {
+	saved, err := makeRaw(os.Stdin)
+	if err != nil {
+		return nil, err
+	}
+	t := &Terminal{in: os.Stdin, out: os.Stdout, saved: saved}
+	// alternate screen, cursor hidden
+	_, err = t.out.WriteString("\x1b[?1049h\x1b[?25l")
+	if err != nil {
+		t.Close()
+		return nil, err
+	}
+	return t, nil
+}
+
+func (t *Terminal) Close() error {
+	t.out.WriteString("\x1b[?25h\x1b[?1049l")
+	return restore(t.in, t.saved)
+}
+
+// Size returns the width and height of the terminal in cells.
+func (t *Terminal) Size() (width, height int, err error) {
+	return size(t.out)
+}
+
+// Size returns the width and height of the terminal f writes to.
+func Size(f *os.File) (width, height int, err error) {
+	return size(f)
+}
+
+// Resized receives a value whenever the terminal changes size.
+func (t *Terminal) Resized() <-chan os.Signal {
+	return notifyResize()
+}
+
+// Draw replaces the screen with lines, which should already fit its width.
+func (t *Terminal) Draw(lines []string) error {
+	var b strings.Builder
+	b.WriteString("\x1b[H")
+	for i, line := range lines {
+		if i > 0 {
+			b.WriteString("\r\n")
+		}
+		// clear what's left of the line from the last frame
+		b.WriteString(line + Reset + "\x1b[K")
+	}
+	b.WriteString("\x1b[J")
+	_, err := t.out.WriteString(b.String())
+	return err
+}
+
+// Key is a key press: a printable rune, or one of the named keys below.
+type Key string
+
+const (
+	KeyUp        Key = "up"
+	KeyDown      Key = "down"
+	KeyLeft      Key = "left"
+	KeyRight     Key = "right"
+	KeyHome      Key = "home"
+	KeyEnd       Key = "end"
+	KeyPageUp    Key = "pgup"
+	KeyPageDown  Key = "pgdn"
+	KeyEnter     Key = "enter"
+	KeyTab       Key = "tab"
+	KeyBackTab   Key = "backtab"
+	KeyEscape    Key = "esc"
+	KeyBackspace Key = "backspace"
+	KeyCtrlC     Key = "ctrl-c"
+)
+
+var escapeKeys = map[string]Key{
+	"[A": KeyUp, "[B": KeyDown, "[C": KeyRight, "[D": KeyLeft,
+	"OA": KeyUp, "OB": KeyDown, "OC": KeyRight, "OD": KeyLeft,
+	"[H": KeyHome, "[F": KeyEnd, "OH": KeyHome, "OF": KeyEnd,
+	"[1~": KeyHome, "[4~": KeyEnd, "[7~": KeyHome, "[8~": KeyEnd,
+	"[5~": KeyPageUp, "[6~": KeyPageDown, "[Z": KeyBackTab,
+}
+
+// Keys reads key presses until input ends, closing the channel then.
+func (t *Terminal) Keys() <-chan Key {
+	keys := make(chan Key)
+	go func() {
+		defer close(keys)
+		buf := make([]byte, 256)
+		for {
+			n, err := t.in.Read(buf)
+			if err != nil {
+				return
+			}
+			for _, key := range decode(buf[:n]) {
+				keys <- key
+			}
+		}
+	}()
+	return keys
+}
+
+// decode splits what one read returned into keys. An escape sequence arrives
+// in a single read, so a lone escape byte is the escape key itself.
+func decode(input []byte) []Key {
+	var keys []Key
+	for len(input) > 0 {
+		switch b := input[0]; {
+		case b == 0x1b && len(input) > 1:
+			end := 2
+			if input[1] == '[' || input[1] == 'O' {
+				// parameters, then a final byte from @ to ~
+				for end < len(input) && (input[end] < 0x40 || input[end] > 0x7e) {
+					end++
+				}
+				end = min(end+1, len(input))
+			}
+			if key, ok := escapeKeys[string(input[1:end])]; ok {
+				keys = append(keys, key)
+			} else if end == 2 {
+				// alt and a key, taken as the key
+				keys = append(keys, decode(input[1:2])...)
+			}
+			input = input[end:]
+			continue
+		case b == 0x1b:
+			keys = append(keys, KeyEscape)
+		case b == '\r' || b == '\n':
+			keys = append(keys, KeyEnter)
+		case b == '\t':
+			keys = append(keys, KeyTab)
+		case b == 0x7f || b == 0x08:
+			keys = append(keys, KeyBackspace)
+		case b == 0x03:
+			keys = append(keys, KeyCtrlC)
+		case b < 0x20:
+			// other control keys do nothing here
+		default:
+			r, size := utf8.DecodeRune(input)
+			keys = append(keys, Key(string(r)))
+			input = input[size:]
+			continue
+		}
+		input = input[1:]
+	}
+	return keys
+}
+
+// Fit pads or cuts text to exactly width cells, counting a rune as a cell.
+// Escape codes in text take no room.
+func Fit(text string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	if visible := Width(text); visible <= width {
+		return text + strings.Repeat(" ", width-visible)
+	}
+	var b strings.Builder
+	styled := false
+	for i, cells := 0, 0; i < len(text) && cells < width-1; {
+		if text[i] == 0x1b {
+			end := escapeEnd(text, i)
+			b.WriteString(text[i:end])
+			styled = true
+			i = end
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(text[i:])
+		b.WriteString(text[i : i+size])
+		cells++
+		i += size
+	}
+	b.WriteString("…")
+	if styled {
+		// don't let a cut off style run into what follows
+		b.WriteString(Reset)
+	}
+	return b.String()
+}
+
+// Width counts the cells text takes, leaving out escape codes.
+func Width(text string) int {
+	cells := 0
+	for i := 0; i < len(text); {
+		if text[i] == 0x1b {
+			i = escapeEnd(text, i)
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(text[i:])
+		cells++
+		i += size
+	}
+	return cells
+}
+
+// escapeEnd finds the end of the escape code starting at i.
+func escapeEnd(text string, i int) int {
+	end := strings.IndexByte(text[i:], 'm')
+	if end < 0 {
+		return len(text)
+	}
+	return i + end + 1
+}
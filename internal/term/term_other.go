@@ -0,0 +1,23 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd
+
+package term
+
+import "os"
+
+type termios struct{}
+
+func makeRaw(*os.File) (*termios, error) {
+	return nil, ErrUnsupported
+}
+
+func restore(*os.File, *termios) error {
+	return ErrUnsupported
+}
+
+func size(*os.File) (width, height int, err error) {
+	return 0, 0, ErrUnsupported
+}
+
+func notifyResize() <-chan os.Signal {
+	return make(chan os.Signal)
+}
@@ -0,0 +1,199 @@
+// Package websub implements the subscriber side of the WebSub protocol:
+// sending (un)subscription requests to a hub and answering the hub's
+// verification and content distribution requests on a callback URL.
+package websub
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ModeSubscribe   = "subscribe"
+	ModeUnsubscribe = "unsubscribe"
+	ModeDenied      = "denied"
+)
+
+const maxContentBytes = 10 << 20
+
+type Request struct {
+	Mode     string
+	Hub      string
+	Topic    string
+	Callback string
+	Secret   string
+	Lease    time.Duration
+}
+
+// Send delivers a subscription request to the hub. A nil error only means the
+// hub accepted the request; the subscription is active once the hub has
+// verified it against the callback.
+func Send(ctx context.Context, client *http.Client, req Request) error {
+	form := url.Values{}
+	form.Set("hub.mode", req.Mode)
+	form.Set("hub.topic", req.Topic)
+	form.Set("hub.callback", req.Callback)
+	if req.Secret != "" {
+		form.Set("hub.secret", req.Secret)
+	}
+	if req.Lease > 0 {
+		form.Set("hub.lease_seconds", strconv.Itoa(int(req.Lease.Seconds())))
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", req.Hub, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	httpReq.Header.Set("User-Agent", "gator")
+
+	res, err := client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		return fmt.Errorf("Hub [%s] rejected %s request: %s %s", req.Hub, req.Mode, res.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// Sign returns an X-Hub-Signature header value for body, as a hub would send it.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func VerifySignature(secret, header string, body []byte) bool {
+	method, signature, ok := strings.Cut(header, "=")
+	if !ok {
+		return false
+	}
+
+	var newHash func() hash.Hash
+	switch strings.ToLower(method) {
+	case "sha1":
+		newHash = sha1.New
+	case "sha256":
+		newHash = sha256.New
+	case "sha384":
+		newHash = sha512.New384
+	case "sha512":
+		newHash = sha512.New
+	default:
+		return false
+	}
+
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(newHash, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+type Subscription struct {
+	Topic  string
+	Secret string
+	// Pending is the mode of the request last sent to the hub, the only
+	// one the hub may verify.
+	Pending string
+}
+
+// Handler serves callback URLs whose last path segment identifies the
+// subscription. Lookup, Verify and Deliver connect it to the caller's storage.
+type Handler struct {
+	Lookup  func(ctx context.Context, id string) (Subscription, error)
+	Verify  func(ctx context.Context, id, mode string, lease time.Duration) error
+	Deliver func(ctx context.Context, id string, body []byte) error
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id := path.Base(r.URL.Path)
+	sub, err := h.Lookup(r.Context(), id)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.verify(w, r, id, sub)
+	case http.MethodPost:
+		h.deliver(w, r, id, sub)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *Handler) verify(w http.ResponseWriter, r *http.Request, id string, sub Subscription) {
+	query := r.URL.Query()
+	mode := query.Get("hub.mode")
+
+	switch mode {
+	case ModeSubscribe, ModeUnsubscribe:
+		// verifying only what was asked for keeps anyone else from
+		// (un)subscribing the callback
+		if query.Get("hub.topic") != sub.Topic || mode != sub.Pending {
+			http.NotFound(w, r)
+			return
+		}
+		var lease time.Duration
+		if mode == ModeSubscribe {
+			seconds, err := strconv.Atoi(query.Get("hub.lease_seconds"))
+			if err != nil {
+				http.Error(w, "invalid hub.lease_seconds", http.StatusBadRequest)
+				return
+			}
+			lease = time.Duration(seconds) * time.Second
+		}
+		if err := h.Verify(r.Context(), id, mode, lease); err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		io.WriteString(w, query.Get("hub.challenge"))
+	case ModeDenied:
+		h.Verify(r.Context(), id, mode, 0)
+		w.WriteHeader(http.StatusOK)
+	default:
+		http.Error(w, "unknown hub.mode", http.StatusBadRequest)
+	}
+}
+
+func (h *Handler) deliver(w http.ResponseWriter, r *http.Request, id string, sub Subscription) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxContentBytes))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	// Content with a missing or bad signature is acknowledged but dropped,
+	// so the hub does not keep redelivering it.
+	if sub.Secret != "" && !VerifySignature(sub.Secret, r.Header.Get("X-Hub-Signature"), body) {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	if err := h.Deliver(r.Context(), id, body); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
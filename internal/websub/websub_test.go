@@ -0,0 +1,268 @@
+package websub
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeHub records the subscription requests it's sent and plays the hub's
+// side of verification and delivery against the callback.
+type fakeHub struct {
+	mu       sync.Mutex
+	requests []url.Values
+}
+
+func (h *fakeHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if r.PostForm.Get("hub.topic") == "" || r.PostForm.Get("hub.callback") == "" {
+		http.Error(w, "missing hub.topic or hub.callback", http.StatusBadRequest)
+		return
+	}
+	h.mu.Lock()
+	h.requests = append(h.requests, r.PostForm)
+	h.mu.Unlock()
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (h *fakeHub) last(t *testing.T) url.Values {
+	t.Helper()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.requests) == 0 {
+		t.Fatal("hub received no requests")
+	}
+	return h.requests[len(h.requests)-1]
+}
+
+// verify sends the hub's verification request for a subscription request and
+// returns the callback's status and body.
+func (h *fakeHub) verify(t *testing.T, form url.Values, challenge string) (int, string) {
+	t.Helper()
+	query := url.Values{}
+	query.Set("hub.mode", form.Get("hub.mode"))
+	query.Set("hub.topic", form.Get("hub.topic"))
+	query.Set("hub.challenge", challenge)
+	query.Set("hub.lease_seconds", form.Get("hub.lease_seconds"))
+	res, err := http.Get(form.Get("hub.callback") + "?" + query.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res.StatusCode, string(body)
+}
+
+// deliver posts content to the callback with the given signature header.
+func (h *fakeHub) deliver(t *testing.T, callback, signature, body string) int {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, callback, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/atom+xml")
+	if signature != "" {
+		req.Header.Set("X-Hub-Signature", signature)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	return res.StatusCode
+}
+
+// subscriber is a callback for a single subscription that records what the
+// hub verified and delivered.
+type subscriber struct {
+	id  string
+	sub Subscription
+
+	mu        sync.Mutex
+	verified  []string
+	lease     time.Duration
+	delivered []string
+}
+
+func (s *subscriber) handler() *Handler {
+	return &Handler{
+		Lookup: func(_ context.Context, id string) (Subscription, error) {
+			if id != s.id {
+				return Subscription{}, errors.New("no such subscription")
+			}
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			return s.sub, nil
+		},
+		Verify: func(_ context.Context, _ string, mode string, lease time.Duration) error {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			s.verified = append(s.verified, mode)
+			s.lease = lease
+			return nil
+		},
+		Deliver: func(_ context.Context, _ string, body []byte) error {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			s.delivered = append(s.delivered, string(body))
+			return nil
+		},
+	}
+}
+
+// state returns what the hub has verified and delivered so far.
+func (s *subscriber) state() (verified []string, lease time.Duration, delivered []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.verified...), s.lease, append([]string(nil), s.delivered...)
+}
+
+func TestSubscribe(t *testing.T) {
+	hub := &fakeHub{}
+	hubServer := httptest.NewServer(hub)
+	defer hubServer.Close()
+
+	sub := &subscriber{id: "abc", sub: Subscription{Topic: "https://example.com/feed.xml", Secret: "s3cret", Pending: ModeSubscribe}}
+	callbackServer := httptest.NewServer(sub.handler())
+	defer callbackServer.Close()
+	callback := callbackServer.URL + "/websub/" + sub.id
+
+	// the subscribe request
+	err := Send(context.Background(), hubServer.Client(), Request{
+		Mode:     ModeSubscribe,
+		Hub:      hubServer.URL,
+		Topic:    sub.sub.Topic,
+		Callback: callback,
+		Secret:   sub.sub.Secret,
+		Lease:    time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	form := hub.last(t)
+	want := map[string]string{
+		"hub.mode":          ModeSubscribe,
+		"hub.topic":         sub.sub.Topic,
+		"hub.callback":      callback,
+		"hub.secret":        sub.sub.Secret,
+		"hub.lease_seconds": "3600",
+	}
+	for key, value := range want {
+		if got := form.Get(key); got != value {
+			t.Errorf("hub got %s = %q, want %q", key, got, value)
+		}
+	}
+
+	// the hub verifies the subscription with a challenge
+	status, body := hub.verify(t, form, "challenge-123")
+	if status != http.StatusOK || body != "challenge-123" {
+		t.Fatalf("verification got %d %q, want 200 %q", status, body, "challenge-123")
+	}
+	if verified, lease, _ := sub.state(); len(verified) != 1 || verified[0] != ModeSubscribe || lease != time.Hour {
+		t.Fatalf("verified %v with lease %v, want [subscribe] with lease 1h", verified, lease)
+	}
+
+	// content signed with the secret is delivered
+	content := "<feed><entry><title>New</title></entry></feed>"
+	if status := hub.deliver(t, callback, Sign(sub.sub.Secret, []byte(content)), content); status != http.StatusNoContent {
+		t.Fatalf("signed delivery got %d, want %d", status, http.StatusNoContent)
+	}
+	if _, _, delivered := sub.state(); len(delivered) != 1 || delivered[0] != content {
+		t.Fatalf("delivered %q, want [%q]", delivered, content)
+	}
+
+	// content with a bad or missing signature is acknowledged but dropped
+	for _, signature := range []string{Sign("wrong", []byte(content)), "sha256=zz", "md5=abc", ""} {
+		if status := hub.deliver(t, callback, signature, content); status != http.StatusAccepted {
+			t.Errorf("delivery signed %q got %d, want %d", signature, status, http.StatusAccepted)
+		}
+	}
+	if _, _, delivered := sub.state(); len(delivered) != 1 {
+		t.Fatalf("delivered %d times, want badly signed content dropped", len(delivered))
+	}
+}
+
+func TestVerifyRejectsOtherTopics(t *testing.T) {
+	sub := &subscriber{id: "abc", sub: Subscription{Topic: "https://example.com/feed.xml", Pending: ModeSubscribe}}
+	callbackServer := httptest.NewServer(sub.handler())
+	defer callbackServer.Close()
+
+	hub := &fakeHub{}
+	form := url.Values{}
+	form.Set("hub.mode", ModeSubscribe)
+	form.Set("hub.topic", "https://example.com/other.xml")
+	form.Set("hub.callback", callbackServer.URL+"/websub/abc")
+	form.Set("hub.lease_seconds", "60")
+	if status, body := hub.verify(t, form, "challenge"); status != http.StatusNotFound || body == "challenge" {
+		t.Fatalf("verification of another topic got %d %q, want 404", status, body)
+	}
+
+	form.Set("hub.topic", sub.sub.Topic)
+	form.Set("hub.callback", callbackServer.URL+"/websub/unknown")
+	if status, _ := hub.verify(t, form, "challenge"); status != http.StatusNotFound {
+		t.Fatalf("verification of an unknown subscription got %d, want 404", status)
+	}
+	if verified, _, _ := sub.state(); len(verified) != 0 {
+		t.Fatalf("verified %v, want nothing", verified)
+	}
+}
+
+func TestVerifyRejectsUnrequestedModes(t *testing.T) {
+	sub := &subscriber{id: "abc", sub: Subscription{Topic: "https://example.com/feed.xml"}}
+	callbackServer := httptest.NewServer(sub.handler())
+	defer callbackServer.Close()
+
+	hub := &fakeHub{}
+	form := url.Values{}
+	form.Set("hub.topic", sub.sub.Topic)
+	form.Set("hub.callback", callbackServer.URL+"/websub/abc")
+	form.Set("hub.lease_seconds", "60")
+	tests := []struct {
+		pending string
+		mode    string
+	}{
+		{pending: "", mode: ModeSubscribe},
+		{pending: "", mode: ModeUnsubscribe},
+		{pending: ModeSubscribe, mode: ModeUnsubscribe},
+		{pending: ModeUnsubscribe, mode: ModeSubscribe},
+	}
+	for _, tt := range tests {
+		sub.mu.Lock()
+		sub.sub.Pending = tt.pending
+		sub.mu.Unlock()
+		form.Set("hub.mode", tt.mode)
+		if status, body := hub.verify(t, form, "challenge"); status != http.StatusNotFound || body == "challenge" {
+			t.Errorf("verification of %s with %q pending got %d %q, want 404", tt.mode, tt.pending, status, body)
+		}
+	}
+	if verified, _, _ := sub.state(); len(verified) != 0 {
+		t.Fatalf("verified %v, want nothing", verified)
+	}
+}
+
+func TestSendRejected(t *testing.T) {
+	hubServer := httptest.NewServer(&fakeHub{})
+	defer hubServer.Close()
+
+	err := Send(context.Background(), hubServer.Client(), Request{Mode: ModeSubscribe, Hub: hubServer.URL})
+	if err == nil || !strings.Contains(err.Error(), "400") {
+		t.Fatalf("Send without a topic got %v, want the hub's 400", err)
+	}
+}
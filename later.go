@@ -0,0 +1,248 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jdwalkerzhere/gator/internal/database"
+)
+
+func handlerLater(s *state, cmd command) error {
+	flags := newFlagSet(cmd)
+	remove := flags.Bool("remove", false, "take the posts off the read-later queue instead")
+	err := flags.Parse(cmd.args)
+	if err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		return fmt.Errorf("No posts provided, please provide post IDs or URLs\n")
+	}
+
+	user, err := s.db.GetUser(context.Background(), s.cfg.CurrentUser)
+	if err != nil {
+		return err
+	}
+
+	for _, ref := range flags.Args() {
+		post, err := findPost(context.Background(), s, ref)
+		if err != nil {
+			return err
+		}
+
+		if *remove {
+			removeParams := database.RemoveFromReadLaterParams{
+				UserID: user.ID,
+				PostID: post.ID,
+			}
+			removed, err := s.db.RemoveFromReadLater(context.Background(), removeParams)
+			if err != nil {
+				return err
+			}
+			if removed == 0 {
+				fmt.Printf("[%s] is not in your read-later queue\n", post.Title)
+			} else {
+				fmt.Printf("Removed [%s] from your read-later queue\n", post.Title)
+			}
+			continue
+		}
+
+		followParams := database.IsFollowingFeedParams{
+			UserID: user.ID,
+			FeedID: post.FeedID,
+		}
+		following, err := s.db.IsFollowingFeed(context.Background(), followParams)
+		if err != nil {
+			return err
+		}
+		if !following {
+			return fmt.Errorf("User [%s] does not follow the feed of [%s]\n", user.Name, post.Title)
+		}
+
+		addParams := database.AddToReadLaterParams{
+			UserID:    user.ID,
+			PostID:    post.ID,
+			CreatedAt: time.Now(),
+		}
+		added, err := s.db.AddToReadLater(context.Background(), addParams)
+		if err != nil {
+			return err
+		}
+		if added == 0 {
+			fmt.Printf("[%s] is already in your read-later queue\n", post.Title)
+		} else {
+			fmt.Printf("Added [%s] to your read-later queue\n", post.Title)
+		}
+	}
+	return nil
+}
+
+func handlerQueue(s *state, cmd command) error {
+	if err := cmd.parseNoFlags(); err != nil {
+		return err
+	}
+	user, err := s.db.GetUser(context.Background(), s.cfg.CurrentUser)
+	if err != nil {
+		return err
+	}
+
+	queue, err := s.db.GetReadLaterQueue(context.Background(), user.ID)
+	if err != nil {
+		return err
+	}
+	records := make([]queuedRecord, len(queue))
+	for i, post := range queue {
+		records[i] = queuedRecord{
+			Position:    i + 1,
+			ID:          post.ID,
+			Title:       post.Title,
+			URL:         post.Url,
+			PublishedAt: post.PublishedAt,
+		}
+	}
+	return printRecords(s, records, func() {
+		if len(records) == 0 {
+			fmt.Println("Your read-later queue is empty")
+		}
+		for _, post := range records {
+			fmt.Printf("%d. %s\n\tID: %s\n\tLink: %s\n\n", post.Position, post.Title, post.ID, post.URL)
+		}
+	})
+}
+
+type queuedRecord struct {
+	Position    int       `json:"position"`
+	ID          uuid.UUID `json:"id"`
+	Title       string    `json:"title"`
+	URL         string    `json:"url"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+func handlerReorder(s *state, cmd command) error {
+	if err := cmd.parseNoFlags(); err != nil {
+		return err
+	}
+	if len(cmd.args) < 2 {
+		return fmt.Errorf("Insufficient arguments, please provide both a post and its new position\n")
+	}
+	position, err := strconv.Atoi(cmd.args[1])
+	if err != nil || position < 1 {
+		return fmt.Errorf("Error parsing position [%s], please provide a number from 1\n", cmd.args[1])
+	}
+
+	user, err := s.db.GetUser(context.Background(), s.cfg.CurrentUser)
+	if err != nil {
+		return err
+	}
+	post, err := findPost(context.Background(), s, cmd.args[0])
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.sqlDB.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	qtx := s.db.WithTx(tx)
+
+	queue, err := qtx.GetReadLaterQueue(context.Background(), user.ID)
+	if err != nil {
+		return err
+	}
+	current := -1
+	for i, queued := range queue {
+		if queued.ID == post.ID {
+			current = i
+		}
+	}
+	if current < 0 {
+		return fmt.Errorf("[%s] is not in your read-later queue\n", post.Title)
+	}
+
+	moved := queue[current]
+	queue = append(queue[:current], queue[current+1:]...)
+	target := min(position-1, len(queue))
+	queue = append(queue[:target], append([]database.GetReadLaterQueueRow{moved}, queue[target:]...)...)
+
+	for i, queued := range queue {
+		positionParams := database.SetReadLaterPositionParams{
+			UserID:   user.ID,
+			PostID:   queued.ID,
+			Position: int32(i + 1),
+		}
+		err = qtx.SetReadLaterPosition(context.Background(), positionParams)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Moved [%s] to position %d\n", post.Title, target+1)
+	return nil
+}
+
+func handlerNext(s *state, cmd command) error {
+	if err := cmd.parseNoFlags(); err != nil {
+		return err
+	}
+	user, err := s.db.GetUser(context.Background(), s.cfg.CurrentUser)
+	if err != nil {
+		return err
+	}
+
+	postID, err := s.db.PopReadLater(context.Background(), user.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		// an empty queue is no records rather than an error
+		return printRecords(s, []nextRecord{}, func() {
+			fmt.Println("Your read-later queue is empty")
+		})
+	}
+	if err != nil {
+		return err
+	}
+	post, err := s.db.GetPost(context.Background(), postID)
+	if err != nil {
+		return err
+	}
+
+	content := post.Content
+	if strings.TrimSpace(content) == "" {
+		content = post.Description
+	}
+	record := []nextRecord{{
+		ID:          post.ID,
+		Title:       post.Title,
+		URL:         post.Url,
+		PublishedAt: post.PublishedAt,
+		Content:     content,
+	}}
+	textOptions, err := postTextOptions("auto")
+	if err != nil {
+		return err
+	}
+	return printRecords(s, record, func() {
+		fmt.Printf("%s\n\tID: %s\n\tLink: %s\n", post.Title, post.ID, post.Url)
+		if text := indentedText(content, post.Url, textOptions); text != "" {
+			fmt.Printf("\n%s\n", text)
+		}
+	})
+}
+
+// nextRecord is the post taken off the read-later queue, with its content
+// as the feed's HTML.
+type nextRecord struct {
+	ID          uuid.UUID `json:"id"`
+	Title       string    `json:"title"`
+	URL         string    `json:"url"`
+	PublishedAt time.Time `json:"published_at"`
+	Content     string    `json:"content"`
+}
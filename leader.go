@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"time"
+
+	"github.com/jdwalkerzhere/gator/internal/database"
+)
+
+// Every agg process contends for this advisory lock key; whoever holds it
+// is the only one scheduling fetches against the database.
+const aggLockID int64 = 0x6761746f72
+
+const leaderCheckInterval = 10 * time.Second
+
+// acquireLeadership blocks in standby until this process holds the
+// aggregator lock, and only returns an error once ctx is cancelled. The returned context is cancelled if the lock's session
+// dies, and release must be called once the caller stops leading.
+func acquireLeadership(ctx context.Context, db *sql.DB) (context.Context, func(), error) {
+	standby := false
+	for {
+		conn, err := db.Conn(ctx)
+		if err == nil {
+			var locked bool
+			locked, err = database.New(conn).TryAdvisoryLock(ctx, aggLockID)
+			if err == nil && locked {
+				if standby {
+					fmt.Println("Aggregator lock acquired, taking over as leader")
+				}
+				leaderCtx, cancel := context.WithCancel(ctx)
+				go watchLeadership(leaderCtx, cancel, conn)
+				return leaderCtx, func() {
+					cancel()
+					releaseLeadership(conn)
+				}, nil
+			}
+			conn.Close()
+		}
+
+		// the database being unreachable for a while shouldn't stop agg, so
+		// errors are only reported and the lock tried again on the next tick
+		switch {
+		case ctx.Err() != nil:
+			return nil, nil, ctx.Err()
+		case err != nil:
+			fmt.Printf("Error trying the aggregator lock, retrying in %s: %v\n", leaderCheckInterval, err)
+		case !standby:
+			fmt.Println("Another aggregator is already running, waiting in standby")
+			standby = true
+		}
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		case <-time.After(leaderCheckInterval):
+		}
+	}
+}
+
+// The lock lives as long as the database session holding it, so losing the
+// connection means another instance may already have taken over.
+func watchLeadership(ctx context.Context, cancel context.CancelFunc, conn *sql.Conn) {
+	ticker := time.NewTicker(leaderCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := conn.PingContext(ctx); err != nil && ctx.Err() == nil {
+				fmt.Printf("Lost connection holding the aggregator lock: %v\n", err)
+				cancel()
+				return
+			}
+		}
+	}
+}
+
+func releaseLeadership(conn *sql.Conn) {
+	_, err := database.New(conn).AdvisoryUnlock(context.Background(), aggLockID)
+	if err != nil {
+		// never hand a session that may still hold the lock back to the pool
+		conn.Raw(func(any) error {
+			return driver.ErrBadConn
+		})
+	}
+	conn.Close()
+}
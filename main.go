@@ -3,12 +3,15 @@ package main
 import (
 	"context"
 	"database/sql"
-	"encoding/xml"
+	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
-	"strconv"
+	"os/signal"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,32 +21,44 @@ import (
 )
 
 type state struct {
-	db  *database.Queries
-	cfg *config.Config
-}
-
-type command struct {
-	name string
-	args []string
+	db     *database.Queries
+	cfg    *config.Config
+	sqlDB  *sql.DB
+	output outputFormat
 }
 
 type RSSFeed struct {
 	Channel struct {
-		Title       string    `xml:"title"`
-		Link        string    `xml:"link"`
-		Description string    `xml:"description"`
-		Item        []RSSItem `xml:"item"`
+		Title       string     `xml:"title"`
+		AtomLinks   []AtomLink `xml:"http://www.w3.org/2005/Atom link"`
+		Link        string     `xml:"link"`
+		Description string     `xml:"description"`
+		Language    string     `xml:"language"`
+		Item        []RSSItem  `xml:"item"`
 	} `xml:"channel"`
 }
 
+type AtomLink struct {
+	Rel  string `xml:"rel,attr"`
+	Href string `xml:"href,attr"`
+}
+
 type RSSItem struct {
-	Title       string `xml:"title"`
-	Link        string `xml:"link"`
-	Description string `xml:"description"`
-	PubDate     string `xml:"pubDate"`
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	Description string   `xml:"description"`
+	PubDate     string   `xml:"pubDate"`
+	Author      string   `xml:"author"`
+	Creator     string   `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Categories  []string `xml:"category"`
+	Content     string   `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	GUID        string   `xml:"guid"`
 }
 
 func handlerLogin(s *state, cmd command) error {
+	if err := cmd.parseNoFlags(); err != nil {
+		return err
+	}
 	if len(cmd.args) == 0 {
 		return fmt.Errorf("No username provided, please provide one\n")
 	}
@@ -63,6 +78,9 @@ func handlerLogin(s *state, cmd command) error {
 }
 
 func handlerRegister(s *state, cmd command) error {
+	if err := cmd.parseNoFlags(); err != nil {
+		return err
+	}
 	if len(cmd.args) == 0 {
 		return fmt.Errorf("No username provided, please provide one\n")
 	}
@@ -84,67 +102,213 @@ func handlerRegister(s *state, cmd command) error {
 	return nil
 }
 
-func handlerReset(s *state, _ command) error {
+func handlerReset(s *state, cmd command) error {
+	if err := cmd.parseNoFlags(); err != nil {
+		return err
+	}
 	s.db.Reset(context.Background())
 	return nil
 }
 
-func handlerGetUsers(s *state, _ command) error {
+type userRecord struct {
+	Name      string    `json:"name"`
+	Current   bool      `json:"current"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func handlerGetUsers(s *state, cmd command) error {
+	if err := cmd.parseNoFlags(); err != nil {
+		return err
+	}
 	users, err := s.db.GetUsers(context.Background())
 	if err != nil {
 		return err
 	}
-	for _, user := range users {
-		if user.Name == s.cfg.CurrentUser {
-			fmt.Printf("* %s (current)\n", user.Name)
-		} else {
-			fmt.Printf("* %s\n", user.Name)
+	records := make([]userRecord, len(users))
+	for i, user := range users {
+		records[i] = userRecord{
+			Name:      user.Name,
+			Current:   user.Name == s.cfg.CurrentUser,
+			CreatedAt: user.CreatedAt,
 		}
 	}
-	return nil
+	return printRecords(s, records, func() {
+		for _, user := range records {
+			if user.Current {
+				fmt.Printf("* %s (current)\n", user.Name)
+			} else {
+				fmt.Printf("* %s\n", user.Name)
+			}
+		}
+	})
 }
 
-func fetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
+type fetchInfo struct {
+	status int
+	bytes  int64
+}
+
+func fetchFeed(ctx context.Context, feedURL string) (*RSSFeed, fetchInfo, error) {
+	info := fetchInfo{}
 	req, err := http.NewRequestWithContext(ctx, "GET", feedURL, nil)
 	if err != nil {
-		return &RSSFeed{}, err
+		return &RSSFeed{}, info, err
 	}
 	req.Header.Set("User-Agent", "gator")
 
 	client := http.Client{}
 	res, err := client.Do(req)
 	if err != nil {
-		return &RSSFeed{}, err
+		return &RSSFeed{}, info, err
 	}
 	defer res.Body.Close()
+	info.status = res.StatusCode
 
 	body, err := io.ReadAll(res.Body)
+	info.bytes = int64(len(body))
 	if err != nil {
-		return &RSSFeed{}, err
+		return &RSSFeed{}, info, err
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return &RSSFeed{}, info, fmt.Errorf("Feed [%s] responded with %s", feedURL, res.Status)
+	}
+	rssFeed, err := parseFeed(body)
+	return rssFeed, info, err
+}
+
+func (f *RSSFeed) hubLinks() (hub, self string) {
+	for _, link := range f.Channel.AtomLinks {
+		switch link.Rel {
+		case "hub":
+			if hub == "" {
+				hub = link.Href
+			}
+		case "self":
+			self = link.Href
+		}
 	}
-	rssFeed := RSSFeed{}
-	err = xml.Unmarshal(body, &rssFeed)
+	return hub, self
+}
+
+type scrapeResult struct {
+	feed      database.Feed
+	startedAt time.Time
+	fetch     fetchInfo
+	itemsSeen int
+	newPosts  int
+}
+
+func scrapeFeeds(ctx context.Context, s *state, status *aggStatus) (scrapeResult, error) {
+	nextFeed, err := s.db.GetNextFeedToFetch(ctx)
 	if err != nil {
-		return &RSSFeed{}, err
+		status.fetchFinished(scrapeResult{}, err)
+		return scrapeResult{}, err
+	}
+	status.fetchStarted(nextFeed)
+	result, err := scrapeFeed(ctx, s, nextFeed)
+	status.fetchFinished(result, err)
+
+	// the history is written even when the fetch ran out of time
+	logErr := logFetch(context.WithoutCancel(ctx), s, result, err)
+	if logErr != nil {
+		fmt.Printf("Error recording fetch history for [%s]: %v\n", nextFeed.Name, logErr)
 	}
-	return &rssFeed, nil
+	return result, err
 }
 
-func scrapeFeeds(s *state) error {
-	nextFeed, err := s.db.GetNextFeedToFetch(context.Background())
+func scrapeFeed(ctx context.Context, s *state, nextFeed database.Feed) (scrapeResult, error) {
+	result := scrapeResult{feed: nextFeed, startedAt: time.Now()}
+	s.db.MarkFeedFetched(ctx, nextFeed.ID)
+	fetchedFeed, info, err := fetchFeed(ctx, nextFeed.Url)
+	result.fetch = info
 	if err != nil {
-		return err
+		return result, err
+	}
+	result.itemsSeen = len(fetchedFeed.Channel.Item)
+
+	hub, self := fetchedFeed.hubLinks()
+	hubParams := database.SetFeedHubParams{
+		ID:       nextFeed.ID,
+		HubUrl:   sql.NullString{String: hub, Valid: hub != ""},
+		HubTopic: sql.NullString{String: self, Valid: hub != "" && self != ""},
 	}
-	s.db.MarkFeedFetched(context.Background(), nextFeed.ID)
-	fetchedFeed, err := fetchFeed(context.Background(), nextFeed.Url)
+	err = s.db.SetFeedHub(ctx, hubParams)
 	if err != nil {
-		return err
+		return result, err
 	}
-	for _, feedItem := range fetchedFeed.Channel.Item {
+
+	if site := strings.TrimSpace(fetchedFeed.Channel.Link); site != "" && site != nextFeed.SiteUrl.String {
+		siteParams := database.SetFeedSiteURLParams{
+			ID:      nextFeed.ID,
+			SiteUrl: sql.NullString{String: site, Valid: true},
+		}
+		err = s.db.SetFeedSiteURL(ctx, siteParams)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	// a language picked with `gator language` is never overridden
+	if config := searchConfigFor(fetchedFeed.Channel.Language); config != "" && !nextFeed.Language.Valid {
+		languageParams := database.DetectFeedLanguageParams{
+			ID:       nextFeed.ID,
+			Language: sql.NullString{String: config, Valid: true},
+		}
+		detected, err := s.db.DetectFeedLanguage(ctx, languageParams)
+		if err != nil {
+			return result, err
+		}
+		if detected > 0 {
+			_, err = s.db.ReindexFeedSearch(ctx, nextFeed.ID)
+			if err != nil {
+				return result, err
+			}
+		}
+	}
+
+	result.newPosts, err = savePosts(ctx, s, nextFeed, fetchedFeed)
+	return result, err
+}
+
+func savePosts(ctx context.Context, s *state, feed database.Feed, rssFeed *RSSFeed) (int, error) {
+	newPosts := 0
+	// readers such as `gator tui` listen for new posts to refresh
+	defer func() {
+		if newPosts > 0 {
+			s.db.NotifyNewPosts(context.WithoutCancel(ctx), feed.ID)
+		}
+	}()
+	for _, feedItem := range rssFeed.Channel.Item {
+		// pruned posts leave a tombstone so the feed can't bring them back
+		tombstoneParams := database.IsPostTombstonedParams{
+			FeedID: feed.ID,
+			Guid:   feedItem.GUID,
+		}
+		if tombstoneParams.Guid == "" {
+			tombstoneParams.Guid = feedItem.Link
+		}
+		tombstoned, err := s.db.IsPostTombstoned(ctx, tombstoneParams)
+		if err != nil {
+			return newPosts, err
+		}
+		if tombstoned {
+			continue
+		}
+
 		timeNow := time.Now()
-		pubDate, err := time.Parse(time.RFC1123Z, feedItem.PubDate)
+		pubDate, err := parsePubDate(feedItem.PubDate)
 		if err != nil {
-			return fmt.Errorf("Error parsing datetime %s into RFC1123Z", feedItem.PubDate)
+			// one bad item shouldn't keep the rest of the feed out
+			fmt.Printf("Skipping [%s] in [%s]: %v\n", feedItem.Title, feed.Name, err)
+			continue
+		}
+		author := feedItem.Author
+		if author == "" {
+			author = feedItem.Creator
+		}
+		categories := []string{}
+		for _, category := range feedItem.Categories {
+			categories = append(categories, strings.TrimSpace(category))
 		}
 		postParams := database.CreatePostParams{
 			ID:          uuid.New(),
@@ -154,58 +318,127 @@ func scrapeFeeds(s *state) error {
 			Url:         feedItem.Link,
 			Description: feedItem.Description,
 			PublishedAt: pubDate,
-			FeedID:      nextFeed.ID,
+			FeedID:      feed.ID,
+			Author:      author,
+			Categories:  categories,
+			Content:     feedItem.Content,
+			Guid:        feedItem.GUID,
 		}
-		s.db.CreatePost(context.Background(), postParams)
-	}
-	return nil
-}
+		post, err := s.db.CreatePost(ctx, postParams)
+		if err != nil {
+			if ctx.Err() != nil {
+				return newPosts, ctx.Err()
+			}
+			// the post was already saved by an earlier fetch
+			continue
+		}
+		newPosts++
 
-func handlerBrowse(s *state, cmd command) error {
-	var limit int32 = 2
-	if len(cmd.args) > 0 {
-		parsedLimit, err := strconv.ParseInt(cmd.args[0], 10, 32)
+		ruleParams := database.ApplyRulesParams{
+			PostID: uuid.NullUUID{UUID: post.ID, Valid: true},
+		}
+		_, err = s.db.ApplyRules(ctx, ruleParams)
 		if err != nil {
-			return err
+			return newPosts, err
 		}
-		limit = int32(parsedLimit)
 	}
-	userId, err := s.db.GetUser(context.Background(), s.cfg.CurrentUser)
+	return newPosts, nil
+}
+
+const aggShutdownGrace = 30 * time.Second
+
+func handlerAgg(s *state, cmd command) error {
+	flags := newFlagSet(cmd)
+	pruneEvery := flags.Duration("prune", 0, "prune old posts at this interval, following the retention settings")
+	err := flags.Parse(cmd.args)
 	if err != nil {
 		return err
 	}
-	postParams := database.GetPostsForUserParams{
-		UserID: userId.ID,
-		Limit:  limit,
+	if flags.NArg() < 1 {
+		return fmt.Errorf("No time fetching argument provided, please provide one\n")
 	}
-	posts, err := s.db.GetPostsForUser(context.Background(), postParams)
+	fetchFrequency, err := time.ParseDuration(flags.Arg(0))
 	if err != nil {
-		return err
+		return fmt.Errorf("Error parsing provided time [%s], please format as {digit}{duration}, duration options [s,m,h]\n", flags.Arg(0))
 	}
-	for i, post := range posts {
-		fmt.Printf("%d. %s\n\tDescription: %s\n\tLink: %s\n\n", i+1, post.Title, post.Description, post.Url)
-	}
-	return nil
-}
 
-func handlerAgg(s *state, cmd command) error {
-	if len(cmd.args) < 1 {
-		return fmt.Errorf("No time fetching argument provided, please provide one\n")
-	}
-	fetchFrequency, err := time.ParseDuration(cmd.args[0])
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// A signal stops the schedule, but a fetch already under way keeps its
+	// own context until the grace period runs out. A second signal after the
+	// first kills the process as usual.
+	workCtx, cancelWork := context.WithCancel(context.Background())
+	defer cancelWork()
+	context.AfterFunc(ctx, func() {
+		stop()
+		fmt.Printf("Shutting down, waiting up to %s for in-flight fetches\n", aggShutdownGrace)
+		time.AfterFunc(aggShutdownGrace, cancelWork)
+	})
+
+	status := newAggStatus(fetchFrequency)
+	stopStatus, err := serveAggStatus(s, status)
 	if err != nil {
-		return fmt.Errorf("Error parsing provided time [%s], please format as {digit}{duration}, duration options [s,m,h]\n", cmd.args[0])
+		fmt.Printf("Status reporting disabled: %v\n", err)
+	} else {
+		defer stopStatus()
 	}
-	ticker := time.NewTicker(fetchFrequency)
-	for ; ; <-ticker.C {
-		err := scrapeFeeds(s)
+
+	for {
+		leaderCtx, release, err := acquireLeadership(ctx, s.sqlDB)
 		if err != nil {
+			if ctx.Err() != nil {
+				fmt.Println(status.summaryLine())
+				return nil
+			}
 			return err
 		}
+
+		status.setLeader(true)
+		runAggregator(leaderCtx, workCtx, s, fetchFrequency, *pruneEvery, status)
+		status.setLeader(false)
+		release()
+		if ctx.Err() != nil {
+			fmt.Println(status.summaryLine())
+			return nil
+		}
+		fmt.Println("No longer the leading aggregator, returning to standby")
+	}
+}
+
+func runAggregator(ctx, workCtx context.Context, s *state, fetchFrequency, pruneEvery time.Duration, status *aggStatus) {
+	ticker := time.NewTicker(fetchFrequency)
+	defer ticker.Stop()
+	lastPruned := time.Time{}
+	for {
+		result, err := scrapeFeeds(workCtx, s, status)
+		if err != nil {
+			fmt.Printf("Error fetching [%s]: %v\n", result.feed.Name, err)
+		}
+
+		if pruneEvery > 0 && time.Since(lastPruned) >= pruneEvery {
+			pruned, err := prunePosts(workCtx, s)
+			if err != nil {
+				fmt.Printf("Error pruning posts: %v\n", err)
+			} else if pruned > 0 {
+				fmt.Printf("Pruned %d old posts\n", pruned)
+			}
+			lastPruned = time.Now()
+		}
+
+		status.scheduled(time.Now().Add(fetchFrequency))
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
 	}
 }
 
 func handlerAddFeed(s *state, cmd command) error {
+	if err := cmd.parseNoFlags(); err != nil {
+		return err
+	}
 	if len(cmd.args) < 2 {
 		return fmt.Errorf("Insufficient arguments, please provide both feed name and url\n")
 	}
@@ -242,22 +475,41 @@ func handlerAddFeed(s *state, cmd command) error {
 		return err
 	}
 
-	fmt.Println(feed)
-	return nil
+	record := []feedRecord{{Name: feed.Name, URL: feed.Url, AddedBy: currentUser.Name}}
+	return printRecords(s, record, func() {
+		fmt.Printf("Feed [%s] Added And Followed: %s\n", feed.Name, feed.Url)
+	})
 }
 
-func handlerFeeds(s *state, _ command) error {
+type feedRecord struct {
+	Name    string `json:"name"`
+	URL     string `json:"url"`
+	AddedBy string `json:"added_by"`
+}
+
+func handlerFeeds(s *state, cmd command) error {
+	if err := cmd.parseNoFlags(); err != nil {
+		return err
+	}
 	feeds, err := s.db.GetFeeds(context.Background())
 	if err != nil {
 		return err
 	}
-	for _, feed := range feeds {
-		fmt.Printf("Name: %s\n\t- URL: %s\n\t- Added By: %s\n", feed.FeedName, feed.Url, feed.UserName)
+	records := make([]feedRecord, len(feeds))
+	for i, feed := range feeds {
+		records[i] = feedRecord{Name: feed.FeedName, URL: feed.Url, AddedBy: feed.UserName}
 	}
-	return nil
+	return printRecords(s, records, func() {
+		for _, feed := range records {
+			fmt.Printf("Name: %s\n\t- URL: %s\n\t- Added By: %s\n", feed.Name, feed.URL, feed.AddedBy)
+		}
+	})
 }
 
 func handlerFollow(s *state, cmd command) error {
+	if err := cmd.parseNoFlags(); err != nil {
+		return err
+	}
 	if len(cmd.args) < 1 {
 		return fmt.Errorf("No URL Provided, please provide one\n")
 	}
@@ -290,24 +542,67 @@ func handlerFollow(s *state, cmd command) error {
 	return nil
 }
 
-func handlerFollowing(s *state, _ command) error {
+// followingRecord is a followed feed in one of its folders. A feed filed in
+// several folders has a record for each.
+type followingRecord struct {
+	Name    string  `json:"name"`
+	URL     string  `json:"url"`
+	SiteURL *string `json:"site_url"`
+	Folder  *string `json:"folder"`
+}
+
+func handlerFollowing(s *state, cmd command) error {
+	if err := cmd.parseNoFlags(); err != nil {
+		return err
+	}
 	user, err := s.db.GetUser(context.Background(), s.cfg.CurrentUser)
 	if err != nil {
 		return err
 	}
 
-	feedsFollowing, err := s.db.GetFeedFollowsForUser(context.Background(), user.ID)
+	feedsFollowing, err := s.db.GetFollowingByFolder(context.Background(), user.ID)
 	if err != nil {
 		return err
 	}
+	if s.output != outputText {
+		records := make([]followingRecord, len(feedsFollowing))
+		for i, feed := range feedsFollowing {
+			records[i] = followingRecord{
+				Name:    feed.FeedName,
+				URL:     feed.Url,
+				SiteURL: stringPtr(feed.SiteUrl),
+				Folder:  stringPtr(feed.FolderName),
+			}
+		}
+		return printRecords(s, records, nil)
+	}
+
+	// without any folders the list stays flat
+	if len(feedsFollowing) == 0 || !feedsFollowing[0].FolderName.Valid {
+		for _, feed := range feedsFollowing {
+			fmt.Println(feed.FeedName)
+		}
+		return nil
+	}
 
-	for _, feed := range feedsFollowing {
-		fmt.Println(feed.FeedName)
+	// feeds arrive grouped by folder, with unfiled feeds last
+	for i, feed := range feedsFollowing {
+		if i == 0 || feed.FolderName != feedsFollowing[i-1].FolderName {
+			if feed.FolderName.Valid {
+				fmt.Printf("%s:\n", feed.FolderName.String)
+			} else {
+				fmt.Println("Unfiled:")
+			}
+		}
+		fmt.Printf("\t- %s\n", feed.FeedName)
 	}
 	return nil
 }
 
 func handlerUnfollow(s *state, cmd command) error {
+	if err := cmd.parseNoFlags(); err != nil {
+		return err
+	}
 	if len(cmd.args) < 1 {
 		return fmt.Errorf("No URL provided to unfollow, please provide one")
 	}
@@ -331,26 +626,6 @@ func handlerUnfollow(s *state, cmd command) error {
 	return nil
 }
 
-type commands struct {
-	commandMap map[string]func(*state, command) error
-}
-
-func (c *commands) register(name string, f func(*state, command) error) {
-	c.commandMap[name] = f
-}
-
-func (c *commands) run(s *state, cmd command) error {
-	command, ok := c.commandMap[cmd.name]
-	if !ok {
-		return fmt.Errorf("No command [%s] registered in the CLI\n", cmd.name)
-	}
-	err := command(s, cmd)
-	if err != nil {
-		return err
-	}
-	return nil
-}
-
 func main() {
 	// initializing state and command registry
 	cfg, err := config.Read()
@@ -364,28 +639,309 @@ func main() {
 	}
 	dbQueries := database.New(db)
 
-	stateNew := state{dbQueries, &cfg}
-	cmds := commands{make(map[string]func(*state, command) error)}
-	cmds.register("login", handlerLogin)
-	cmds.register("register", handlerRegister)
-	cmds.register("reset", handlerReset)
-	cmds.register("users", handlerGetUsers)
-	cmds.register("agg", handlerAgg)
-	cmds.register("addfeed", handlerAddFeed)
-	cmds.register("feeds", handlerFeeds)
-	cmds.register("follow", handlerFollow)
-	cmds.register("following", handlerFollowing)
-	cmds.register("unfollow", handlerUnfollow)
-	cmds.register("browse", handlerBrowse)
+	stateNew := state{dbQueries, &cfg, db, outputText}
+	cmds := commands{commandMap: make(map[string]func(*state, command) error), help: make(map[string]commandHelp)}
+	cmds.register("login", handlerLogin, commandHelp{
+		group:    "Users",
+		args:     "<username>",
+		summary:  "switch to a registered user",
+		examples: []string{"gator login johndoe"},
+		complete: completeUsers,
+	})
+	cmds.register("register", handlerRegister, commandHelp{
+		group:    "Users",
+		args:     "<username>",
+		summary:  "create a user and switch to it",
+		examples: []string{"gator register johndoe"},
+	})
+	cmds.register("reset", handlerReset, commandHelp{
+		group:   "Users",
+		summary: "delete every user, with their feeds and posts",
+	})
+	cmds.register("users", handlerGetUsers, commandHelp{
+		group:    "Users",
+		summary:  "list the users, marking the current one",
+		examples: []string{"gator users", "gator -o table users"},
+	})
+	cmds.register("agg", handlerAgg, commandHelp{
+		group:    "Aggregation",
+		args:     "<time between fetches>",
+		summary:  "fetch followed feeds until stopped",
+		examples: []string{"gator agg 1m", "gator agg --prune 24h 1m"},
+	})
+	cmds.register("addfeed", handlerAddFeed, commandHelp{
+		group:    "Feeds",
+		args:     "<name> <feed url>",
+		summary:  "add a feed and follow it",
+		examples: []string{`gator addfeed "Go Blog" https://go.dev/blog/feed.atom`},
+	})
+	cmds.register("feeds", handlerFeeds, commandHelp{
+		group:   "Feeds",
+		summary: "list every feed and who added it",
+	})
+	cmds.register("follow", handlerFollow, commandHelp{
+		group:    "Feeds",
+		args:     "<feed url>",
+		summary:  "follow a feed someone added",
+		examples: []string{"gator follow https://go.dev/blog/feed.atom"},
+		complete: completeFeeds,
+	})
+	cmds.register("following", handlerFollowing, commandHelp{
+		group:    "Feeds",
+		summary:  "list the feeds you follow, by folder",
+		examples: []string{"gator following", "gator --output json following | jq -r '.[].url'"},
+	})
+	cmds.register("unfollow", handlerUnfollow, commandHelp{
+		group:    "Feeds",
+		args:     "<feed url>",
+		summary:  "stop following a feed",
+		examples: []string{"gator unfollow https://go.dev/blog/feed.atom"},
+		complete: completeFollowing,
+	})
+	cmds.register("browse", handlerBrowse, commandHelp{
+		group:   "Posts",
+		args:    "[limit]",
+		summary: "list unread posts from the feeds you follow, newest first",
+		examples: []string{
+			"gator browse --all 10",
+			`gator browse --feed "Go Blog" --since 30d --author rsc 10`,
+			`gator browse --query 'folder:news -feed:"Hacker News" is:starred' 10`,
+		},
+	})
+	cmds.register("tui", handlerTUI, commandHelp{
+		group:    "Posts",
+		summary:  "read posts in a full-screen terminal reader",
+		examples: []string{"gator tui", "gator tui --all"},
+	})
+	cmds.register("websub", handlerWebSub, commandHelp{
+		group:    "Aggregation",
+		args:     "<listen address> <public callback url>",
+		summary:  "serve WebSub callbacks so hubs push new posts",
+		examples: []string{"gator websub :8080 https://gator.example.com"},
+	})
+	cmds.register("status", handlerStatus, commandHelp{
+		group:   "Aggregation",
+		summary: "show what a running aggregator is doing",
+	})
+	cmds.register("history", handlerHistory, commandHelp{
+		group:    "Aggregation",
+		args:     "<feed url> [limit]",
+		summary:  "list the recent fetches of a feed",
+		examples: []string{"gator history https://go.dev/blog/feed.atom 5"},
+		complete: byPosition(completeFollowing, nil),
+	})
+	cmds.register("read", handlerRead, commandHelp{
+		group:    "Posts",
+		args:     "[post id or url...]",
+		summary:  "mark posts read",
+		examples: []string{"gator read https://go.dev/blog/go1.22", "gator read --feed https://go.dev/blog/feed.atom", "gator read --older-than 30d"},
+	})
+	cmds.register("unread", handlerUnread, commandHelp{
+		group:   "Posts",
+		args:    "[post id or url...]",
+		summary: "mark posts unread",
+	})
+	cmds.register("star", handlerStar, commandHelp{
+		group:   "Posts",
+		args:    "<post id or url>...",
+		summary: "star posts to keep them",
+	})
+	cmds.register("unstar", handlerUnstar, commandHelp{
+		group:   "Posts",
+		args:    "<post id or url>...",
+		summary: "take the star off posts",
+	})
+	cmds.register("saved", handlerSaved, commandHelp{
+		group:   "Posts",
+		args:    "[limit]",
+		summary: "list your starred posts",
+	})
+	cmds.register("later", handlerLater, commandHelp{
+		group:    "Posts",
+		args:     "<post id or url>...",
+		summary:  "add posts to the read-later queue",
+		examples: []string{"gator later https://go.dev/blog/go1.22", "gator later --remove https://go.dev/blog/go1.22"},
+	})
+	cmds.register("queue", handlerQueue, commandHelp{
+		group:   "Posts",
+		summary: "list the read-later queue in order",
+	})
+	cmds.register("reorder", handlerReorder, commandHelp{
+		group:    "Posts",
+		args:     "<post id or url> <position>",
+		summary:  "move a post to a position in the queue",
+		examples: []string{"gator reorder https://go.dev/blog/go1.22 1"},
+	})
+	cmds.register("next", handlerNext, commandHelp{
+		group:   "Posts",
+		summary: "show the first post in the queue",
+	})
+	cmds.register("folder", handlerFolder, commandHelp{
+		group:    "Folders",
+		args:     "<folder> <feed url>...",
+		summary:  "file followed feeds under a folder",
+		examples: []string{"gator folder Tech/Go https://go.dev/blog/feed.atom"},
+		complete: byPosition(completeFolders, completeFollowing),
+	})
+	cmds.register("unfolder", handlerUnfolder, commandHelp{
+		group:    "Folders",
+		args:     "<folder> <feed url>...",
+		summary:  "take feeds out of a folder",
+		complete: byPosition(completeFolders, completeFollowing),
+	})
+	cmds.register("folders", handlerFolders, commandHelp{
+		group:   "Folders",
+		summary: "list your folders",
+	})
+	cmds.register("rmfolder", handlerRemoveFolder, commandHelp{
+		group:    "Folders",
+		args:     "<folder>",
+		summary:  "delete a folder, keeping its feeds",
+		complete: byPosition(completeFolders, nil),
+	})
+	cmds.register("search", handlerSearch, commandHelp{
+		group:   "Search and rules",
+		args:    "<query>",
+		summary: "search the posts of the feeds you follow",
+		examples: []string{
+			"gator search generics",
+			`gator search --feed "Go Blog" --since 365d "type parameters" -draft`,
+			`gator search feed:golang author:rsc "generics" after:2024-01-01 is:unread`,
+		},
+	})
+	cmds.register("language", handlerLanguage, commandHelp{
+		group:    "Search and rules",
+		args:     "<feed url> <language or auto>",
+		summary:  "set the language a feed is searched in",
+		examples: []string{"gator language https://example.com/rss german", "gator language https://example.com/rss auto"},
+		complete: byPosition(completeFollowing, fixed("auto"), nil),
+	})
+	cmds.register("savesearch", handlerSaveSearch, commandHelp{
+		group:    "Search and rules",
+		args:     "<name> <query>",
+		summary:  "save a query to browse like a feed",
+		examples: []string{"gator savesearch advisories 'folder:vendors security advisory after:30d'"},
+	})
+	cmds.register("searches", handlerSearches, commandHelp{
+		group:   "Search and rules",
+		summary: "list your saved searches",
+	})
+	cmds.register("rmsearch", handlerRemoveSearch, commandHelp{
+		group:    "Search and rules",
+		args:     "<name>",
+		summary:  "delete a saved search",
+		complete: byPosition(completeSearches, nil),
+	})
+	cmds.register("addrule", handlerAddRule, commandHelp{
+		group:   "Search and rules",
+		args:    "<read|star|hide>",
+		summary: "mark read, star or hide posts matching conditions",
+		examples: []string{
+			"gator addrule --title 'sponsored|giveaway' hide",
+			"gator addrule --feed https://example.com/rss --category release star",
+			"gator addrule --author 'Marketing Team' --dry-run read",
+		},
+		complete: byPosition(fixed("read", "star", "hide"), nil),
+	})
+	cmds.register("rules", handlerRules, commandHelp{
+		group:   "Search and rules",
+		summary: "list your rules",
+	})
+	cmds.register("delrule", handlerDeleteRule, commandHelp{
+		group:   "Search and rules",
+		args:    "<rule id>",
+		summary: "delete a rule",
+	})
+	cmds.register("applyrules", handlerApplyRules, commandHelp{
+		group:    "Search and rules",
+		args:     "[rule id]",
+		summary:  "run rules over the posts you already have",
+		examples: []string{"gator applyrules --dry-run"},
+	})
+	cmds.register("prune", handlerPrune, commandHelp{
+		group:    "Aggregation",
+		summary:  "remove old posts past their retention",
+		examples: []string{"gator prune --dry-run"},
+	})
+	cmds.register("retention", handlerRetention, commandHelp{
+		group:   "Aggregation",
+		summary: "show or set how long posts are kept",
+		examples: []string{
+			"gator retention --max-days 90 --max-posts 500",
+			"gator retention --feed https://example.com/rss --inherit",
+		},
+	})
+	cmds.register("import", handlerImport, commandHelp{
+		group:    "Import and export",
+		args:     "<file>",
+		summary:  "follow the feeds of an OPML file, or restore an archive",
+		examples: []string{"gator import subscriptions.opml", "gator import --archive gator-backup.jsonl"},
+	})
+	cmds.register("export", handlerExport, commandHelp{
+		group:   "Import and export",
+		summary: "write your follows, an archive, or a bundle of posts",
+		examples: []string{
+			"gator export --opml --out subscriptions.opml",
+			"gator export --archive --out gator-backup.jsonl",
+			"gator export --epub --folder Tech --since 7d --out weekend.epub",
+		},
+	})
+	cmds.register("publish", handlerPublish, commandHelp{
+		group:    "Import and export",
+		summary:  "write posts as an Atom or RSS feed",
+		examples: []string{"gator publish > timeline.atom", "gator publish --rss --folder Tech --out tech.rss --link https://example.com/tech.rss"},
+	})
+	cmds.register("help", cmds.handlerHelp, commandHelp{
+		group:    "Help",
+		args:     "[command]",
+		summary:  "list the commands, or show how to use one",
+		examples: []string{"gator help browse"},
+		complete: byPosition(cmds.completeCommands, nil),
+	})
+	cmds.register("completion", handlerCompletion, commandHelp{
+		group:   "Help",
+		args:    "<bash|zsh|fish>",
+		summary: "print a shell completion script",
+		examples: []string{
+			"source <(gator completion bash)",
+			`gator completion zsh > "${fpath[1]}/_gator"`,
+			"gator completion fish > ~/.config/fish/completions/gator.fish",
+		},
+		complete: byPosition(fixed("bash", "zsh", "fish"), nil),
+	})
+	cmds.register("__complete", cmds.handlerComplete, commandHelp{
+		group:  "Help",
+		hidden: true,
+	})
+
+	// global flags come before the command
+	globalFlags := flag.NewFlagSet("gator", flag.ContinueOnError)
+	output := globalFlags.String("output", string(outputText), "print listings as text, table, json, jsonl, csv or tsv")
+	globalFlags.StringVar(output, "o", string(outputText), "shorthand for --output")
+	globalFlags.SetOutput(io.Discard)
+	err = globalFlags.Parse(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		cmds.printOverview(os.Stdout)
+		return
+	}
+	if err != nil {
+		fmt.Printf("%v\nRun `gator help` for usage\n", err)
+		os.Exit(2)
+	}
+	stateNew.output, err = parseOutputFormat(*output)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
 
 	// fetching user cli args
-	args := os.Args
-	if len(args) < 2 {
-		fmt.Println("No arguments provided, please run again")
+	args := globalFlags.Args()
+	if len(args) < 1 {
+		cmds.printOverview(os.Stderr)
 		os.Exit(1)
 	}
 
-	cmd := command{args[1], args[2:]}
+	cmd := command{name: args[0], args: args[1:]}
 	err = cmds.run(&stateNew, cmd)
 	if err != nil {
 		fmt.Println(err)
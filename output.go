@@ -0,0 +1,171 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// outputFormat is how listing commands print their results, picked with the
+// global --output flag. Every format other than text prints the same fields,
+// named by the json tags of the command's record type.
+type outputFormat string
+
+const (
+	outputText  outputFormat = "text"
+	outputTable outputFormat = "table"
+	outputJSON  outputFormat = "json"
+	outputJSONL outputFormat = "jsonl"
+	outputCSV   outputFormat = "csv"
+	outputTSV   outputFormat = "tsv"
+)
+
+var outputFormats = []outputFormat{outputText, outputTable, outputJSON, outputJSONL, outputCSV, outputTSV}
+
+func parseOutputFormat(value string) (outputFormat, error) {
+	for _, format := range outputFormats {
+		if string(format) == strings.ToLower(value) {
+			return format, nil
+		}
+	}
+	names := make([]string, len(outputFormats))
+	for i, format := range outputFormats {
+		names[i] = string(format)
+	}
+	return "", fmt.Errorf("Unknown output format [%s], please provide one of %s\n", value, strings.Join(names, ", "))
+}
+
+// tabular reports whether the format lays records out in rows and columns,
+// which only records without nested lists of records fit.
+func (f outputFormat) tabular() bool {
+	return f == outputTable || f == outputCSV || f == outputTSV
+}
+
+// printRecords prints records, a slice of structs, in the chosen output
+// format. printText prints them for people, the way the command always has.
+func printRecords[T any](s *state, records []T, printText func()) error {
+	if s.output == "" || s.output == outputText {
+		printText()
+		return nil
+	}
+	return writeRecords(os.Stdout, s.output, records)
+}
+
+func writeRecords[T any](w io.Writer, format outputFormat, records []T) error {
+	switch format {
+	case outputJSON:
+		if records == nil {
+			records = []T{}
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(records)
+	case outputJSONL:
+		encoder := json.NewEncoder(w)
+		for _, record := range records {
+			err := encoder.Encode(record)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	fields := recordFields(reflect.TypeFor[T]())
+	header := make([]string, len(fields))
+	for i, field := range fields {
+		header[i] = field.name
+	}
+	rows := make([][]string, len(records))
+	for i, record := range records {
+		value := reflect.ValueOf(record)
+		rows[i] = make([]string, len(fields))
+		for j, field := range fields {
+			rows[i][j] = formatField(value.Field(field.index))
+		}
+	}
+
+	switch format {
+	case outputCSV:
+		writer := csv.NewWriter(w)
+		writer.Write(header)
+		writer.WriteAll(rows)
+		return writer.Error()
+	case outputTSV:
+		// TSV has no quoting, so values can't hold tabs or line breaks
+		cleaner := strings.NewReplacer("\t", " ", "\r\n", " ", "\n", " ", "\r", " ")
+		lines := []string{strings.Join(header, "\t")}
+		for _, row := range rows {
+			for i := range row {
+				row[i] = cleaner.Replace(row[i])
+			}
+			lines = append(lines, strings.Join(row, "\t"))
+		}
+		_, err := io.WriteString(w, strings.Join(lines, "\n")+"\n")
+		return err
+	default:
+		writer := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		cleaner := strings.NewReplacer("\t", " ", "\n", " ", "\r", "")
+		for i := range header {
+			header[i] = strings.ToUpper(header[i])
+		}
+		fmt.Fprintln(writer, strings.Join(header, "\t"))
+		for _, row := range rows {
+			for i := range row {
+				row[i] = cleaner.Replace(row[i])
+			}
+			fmt.Fprintln(writer, strings.Join(row, "\t"))
+		}
+		return writer.Flush()
+	}
+}
+
+type recordField struct {
+	name  string
+	index int
+}
+
+func recordFields(recordType reflect.Type) []recordField {
+	var fields []recordField
+	for i := 0; i < recordType.NumField(); i++ {
+		name, _, _ := strings.Cut(recordType.Field(i).Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		fields = append(fields, recordField{name: name, index: i})
+	}
+	return fields
+}
+
+// formatField writes a value the way it reads in a CSV cell: times in RFC
+// 3339, lists joined with semicolons and missing values left empty.
+func formatField(value reflect.Value) string {
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return ""
+		}
+		value = value.Elem()
+	}
+	switch v := value.Interface().(type) {
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.Format(time.RFC3339)
+	case []string:
+		return strings.Join(v, ";")
+	case bool:
+		return strconv.FormatBool(v)
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
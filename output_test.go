@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type testRecord struct {
+	ID       uuid.UUID  `json:"id"`
+	Name     string     `json:"name"`
+	Tags     []string   `json:"tags,omitempty"`
+	Read     bool       `json:"read"`
+	Seen     *time.Time `json:"seen_at"`
+	At       time.Time  `json:"at"`
+	internal string
+	Skipped  string `json:"-"`
+}
+
+func testRecords() []testRecord {
+	at := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	return []testRecord{
+		{
+			ID:   uuid.MustParse("6f1c3f1e-8b2a-4c55-9d0e-2f4b8a7c1d3e"),
+			Name: "Go, \"the\" blog",
+			Tags: []string{"go", "news"},
+			Read: true,
+			Seen: &at,
+			At:   at,
+		},
+		{
+			ID:   uuid.MustParse("00000000-0000-0000-0000-000000000002"),
+			Name: "tabs\tand\nlines",
+		},
+	}
+}
+
+func TestWriteRecords(t *testing.T) {
+	tests := []struct {
+		format outputFormat
+		want   []string
+	}{
+		{
+			format: outputCSV,
+			want: []string{
+				"id,name,tags,read,seen_at,at",
+				`6f1c3f1e-8b2a-4c55-9d0e-2f4b8a7c1d3e,"Go, ""the"" blog",go;news,true,2024-05-01T12:30:00Z,2024-05-01T12:30:00Z`,
+				"00000000-0000-0000-0000-000000000002,\"tabs\tand\nlines\",,false,,",
+			},
+		},
+		{
+			format: outputTSV,
+			want: []string{
+				"id\tname\ttags\tread\tseen_at\tat",
+				"6f1c3f1e-8b2a-4c55-9d0e-2f4b8a7c1d3e\tGo, \"the\" blog\tgo;news\ttrue\t2024-05-01T12:30:00Z\t2024-05-01T12:30:00Z",
+				"00000000-0000-0000-0000-000000000002\ttabs and lines\t\tfalse\t\t",
+			},
+		},
+		{
+			format: outputTable,
+			want: []string{
+				"ID                                    NAME            TAGS     READ   SEEN_AT               AT",
+				"6f1c3f1e-8b2a-4c55-9d0e-2f4b8a7c1d3e  Go, \"the\" blog  go;news  true   2024-05-01T12:30:00Z  2024-05-01T12:30:00Z",
+				"00000000-0000-0000-0000-000000000002  tabs and lines           false",
+			},
+		},
+		{
+			format: outputJSONL,
+			want: []string{
+				`{"id":"6f1c3f1e-8b2a-4c55-9d0e-2f4b8a7c1d3e","name":"Go, \"the\" blog","tags":["go","news"],"read":true,"seen_at":"2024-05-01T12:30:00Z","at":"2024-05-01T12:30:00Z"}`,
+				`{"id":"00000000-0000-0000-0000-000000000002","name":"tabs\tand\nlines","read":false,"seen_at":null,"at":"0001-01-01T00:00:00Z"}`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			var out bytes.Buffer
+			if err := writeRecords(&out, tt.format, testRecords()); err != nil {
+				t.Fatal(err)
+			}
+			got := out.String()
+			if tt.format == outputTable {
+				// tabwriter pads the empty cells at the end of a row
+				lines := strings.Split(got, "\n")
+				for i := range lines {
+					lines[i] = strings.TrimRight(lines[i], " ")
+				}
+				got = strings.Join(lines, "\n")
+			}
+			if want := strings.Join(tt.want, "\n") + "\n"; got != want {
+				t.Errorf("got\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestWriteRecordsEmpty(t *testing.T) {
+	tests := []struct {
+		format outputFormat
+		want   string
+	}{
+		{format: outputJSON, want: "[]\n"},
+		{format: outputJSONL, want: ""},
+		{format: outputCSV, want: "id,name,tags,read,seen_at,at\n"},
+		{format: outputTSV, want: "id\tname\ttags\tread\tseen_at\tat\n"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			var out bytes.Buffer
+			if err := writeRecords[testRecord](&out, tt.format, nil); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("got %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestParseOutputFormat(t *testing.T) {
+	for _, value := range []string{"text", "JSON", "Tsv"} {
+		if _, err := parseOutputFormat(value); err != nil {
+			t.Errorf("parseOutputFormat(%q): %v", value, err)
+		}
+	}
+	_, err := parseOutputFormat("yaml")
+	if err == nil || !strings.Contains(err.Error(), "text, table, json, jsonl, csv, tsv") {
+		t.Errorf("parseOutputFormat(yaml) error = %v, want the formats listed", err)
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jdwalkerzhere/gator/internal/database"
+)
+
+// tombstoneRetention is how long a pruned post's tombstone keeps it from
+// being added back, long after feeds stop listing it.
+const tombstoneRetention = 180 * 24 * time.Hour
+
+// prunePosts deletes the posts past their feed's retention, leaving starred
+// and queued posts alone, and the tombstones past theirs.
+func prunePosts(ctx context.Context, s *state) (int64, error) {
+	pruned, err := s.db.PrunePosts(ctx)
+	if err != nil {
+		return 0, err
+	}
+	_, err = s.db.PruneTombstones(ctx, time.Now().Add(-tombstoneRetention))
+	return pruned, err
+}
+
+func handlerPrune(s *state, cmd command) error {
+	flags := newFlagSet(cmd)
+	dryRun := flags.Bool("dry-run", false, "show how many posts would be pruned without removing them")
+	err := flags.Parse(cmd.args)
+	if err != nil {
+		return err
+	}
+
+	if !*dryRun {
+		pruned, err := prunePosts(context.Background(), s)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Pruned %d posts\n", pruned)
+		return nil
+	}
+
+	counts, err := s.db.CountPrunablePosts(context.Background())
+	if err != nil {
+		return err
+	}
+	records := make([]prunableRecord, len(counts))
+	for i, count := range counts {
+		records[i] = prunableRecord{Feed: count.Name, URL: count.Url, Posts: count.Prunable}
+	}
+	return printRecords(s, records, func() {
+		var total int64
+		for _, record := range records {
+			fmt.Printf("* %s (%s): %d posts\n", record.Feed, record.URL, record.Posts)
+			total += record.Posts
+		}
+		fmt.Printf("Would prune %d posts\n", total)
+	})
+}
+
+// prunableRecord is how many posts of a feed the next prune removes.
+type prunableRecord struct {
+	Feed  string `json:"feed"`
+	URL   string `json:"url"`
+	Posts int64  `json:"posts"`
+}
+
+func handlerRetention(s *state, cmd command) error {
+	flags := newFlagSet(cmd)
+	feedURL := flags.String("feed", "", "change the retention of the feed with this URL instead of the default")
+	maxDays := flags.Int("max-days", -1, "prune posts published more than this many days ago, 0 keeps them forever")
+	maxPosts := flags.Int("max-posts", -1, "keep at most this many posts per feed, 0 keeps them all")
+	inherit := flags.Bool("inherit", false, "drop the feed's own retention and use the default")
+	err := flags.Parse(cmd.args)
+	if err != nil {
+		return err
+	}
+
+	if *feedURL == "" {
+		if *inherit {
+			return fmt.Errorf("--inherit needs the --feed to reset\n")
+		}
+		if *maxDays < 0 && *maxPosts < 0 {
+			return printRetention(s)
+		}
+		defaults, err := s.db.GetDefaultRetention(context.Background())
+		if err != nil {
+			return err
+		}
+		retentionParams := database.SetDefaultRetentionParams{Days: defaults.Days, Posts: defaults.Posts}
+		if *maxDays >= 0 {
+			retentionParams.Days = int32(*maxDays)
+		}
+		if *maxPosts >= 0 {
+			retentionParams.Posts = int32(*maxPosts)
+		}
+		err = s.db.SetDefaultRetention(context.Background(), retentionParams)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Default retention set to %s\n", describeRetention(int(retentionParams.Days), int(retentionParams.Posts)))
+		return nil
+	}
+
+	feed, err := s.db.GetFeed(context.Background(), *feedURL)
+	if err != nil {
+		return err
+	}
+	retentionParams := database.SetFeedRetentionParams{ID: feed.ID}
+	if !*inherit {
+		retentionParams.RetentionDays = feed.RetentionDays
+		retentionParams.RetentionPosts = feed.RetentionPosts
+		if *maxDays >= 0 {
+			retentionParams.RetentionDays = sql.NullInt32{Int32: int32(*maxDays), Valid: true}
+		}
+		if *maxPosts >= 0 {
+			retentionParams.RetentionPosts = sql.NullInt32{Int32: int32(*maxPosts), Valid: true}
+		}
+	}
+	err = s.db.SetFeedRetention(context.Background(), retentionParams)
+	if err != nil {
+		return err
+	}
+	if *inherit {
+		fmt.Printf("Feed [%s] uses the default retention\n", feed.Name)
+		return nil
+	}
+	defaults, err := s.db.GetDefaultRetention(context.Background())
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Feed [%s] retention set to %s\n", feed.Name, describeRetention(effectiveRetention(defaults, retentionParams.RetentionDays, retentionParams.RetentionPosts)))
+	return nil
+}
+
+// retentionRecord is the retention in effect for a feed with its own, or
+// the default one when Feed and URL are null. Zero keeps posts forever.
+type retentionRecord struct {
+	Feed     *string `json:"feed"`
+	URL      *string `json:"url"`
+	MaxDays  int     `json:"max_days"`
+	MaxPosts int     `json:"max_posts"`
+}
+
+func printRetention(s *state) error {
+	defaults, err := s.db.GetDefaultRetention(context.Background())
+	if err != nil {
+		return err
+	}
+	feeds, err := s.db.GetFeedsWithRetention(context.Background())
+	if err != nil {
+		return err
+	}
+	records := []retentionRecord{{MaxDays: int(defaults.Days), MaxPosts: int(defaults.Posts)}}
+	for _, feed := range feeds {
+		days, posts := effectiveRetention(defaults, feed.RetentionDays, feed.RetentionPosts)
+		records = append(records, retentionRecord{Feed: &feed.Name, URL: &feed.Url, MaxDays: days, MaxPosts: posts})
+	}
+	return printRecords(s, records, func() {
+		for _, record := range records {
+			if record.Feed == nil {
+				fmt.Printf("Default: %s\n", describeRetention(record.MaxDays, record.MaxPosts))
+				continue
+			}
+			fmt.Printf("* %s (%s): %s\n", *record.Feed, *record.URL, describeRetention(record.MaxDays, record.MaxPosts))
+		}
+	})
+}
+
+// effectiveRetention fills in the defaults for whatever the feed leaves unset.
+func effectiveRetention(defaults database.GetDefaultRetentionRow, days, posts sql.NullInt32) (int, int) {
+	effectiveDays, effectivePosts := int(defaults.Days), int(defaults.Posts)
+	if days.Valid {
+		effectiveDays = int(days.Int32)
+	}
+	if posts.Valid {
+		effectivePosts = int(posts.Int32)
+	}
+	return effectiveDays, effectivePosts
+}
+
+func describeRetention(days, posts int) string {
+	switch {
+	case days > 0 && posts > 0:
+		return fmt.Sprintf("keep posts for %d days, at most %d per feed", days, posts)
+	case days > 0:
+		return fmt.Sprintf("keep posts for %d days", days)
+	case posts > 0:
+		return fmt.Sprintf("keep at most %d posts per feed", posts)
+	default:
+		return "keep posts forever"
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jdwalkerzhere/gator/internal/postquery"
+	"github.com/jdwalkerzhere/gator/internal/syndication"
+)
+
+func handlerPublish(s *state, cmd command) error {
+	flags := newFlagSet(cmd)
+	options := addBrowseFlags(flags.FlagSet, true)
+	asAtom := flags.Bool("atom", false, "write an Atom 1.0 feed (the default)")
+	asRSS := flags.Bool("rss", false, "write an RSS 2.0 feed")
+	outPath := flags.String("out", "", "write to this file instead of stdout")
+	limit := flags.Int("limit", 50, "include at most this many posts")
+	title := flags.String("title", "", "title the feed instead of naming it after the selection")
+	link := flags.String("link", "", "URL the feed will be served from, used as its id and self link, needed by --rss for posts from several sites")
+	err := flags.Parse(cmd.args)
+	if err != nil {
+		return err
+	}
+	if *asAtom && *asRSS {
+		return fmt.Errorf("Only one format can be provided, please provide --atom or --rss\n")
+	}
+	if *link != "" {
+		parsed, err := url.Parse(*link)
+		if err != nil || !parsed.IsAbs() {
+			return fmt.Errorf("Invalid link [%s], please provide an absolute URL\n", *link)
+		}
+	}
+
+	user, err := s.db.GetUser(context.Background(), s.cfg.CurrentUser)
+	if err != nil {
+		return err
+	}
+	filter, err := options.filter(context.Background(), s, user, int32(*limit))
+	if err != nil {
+		return err
+	}
+	posts, err := postquery.List(context.Background(), s.sqlDB, filter)
+	if err != nil {
+		return err
+	}
+
+	feed := syndication.Feed{
+		Title:       *title,
+		Description: fmt.Sprintf("Posts from the feeds %s follows in gator", user.Name),
+		Link:        *link,
+		Author:      user.Name,
+		Updated:     time.Now(),
+	}
+	if feed.Title == "" {
+		feed.Title = fmt.Sprintf("%s's gator timeline", user.Name)
+		switch {
+		case *options.search != "":
+			feed.Title = fmt.Sprintf("%s's gator search [%s]", user.Name, *options.search)
+		case *options.folder != "":
+			feed.Title = fmt.Sprintf("%s's gator folder [%s]", user.Name, *options.folder)
+		}
+	}
+	feed.ID = feed.Link
+	if feed.ID == "" {
+		// stable across runs, so readers don't see a new feed every time
+		feed.ID = "urn:uuid:" + uuid.NewSHA1(user.ID, []byte(feed.Title)).String()
+	}
+	if len(posts) > 0 {
+		feed.Updated = posts[0].PublishedAt
+		feed.SiteURL = posts[0].FeedSiteUrl
+	}
+	for _, post := range posts {
+		feed.Items = append(feed.Items, publishedItem(post))
+		// posts from several sites have no one site to link to
+		if post.FeedSiteUrl != feed.SiteURL {
+			feed.SiteURL = ""
+		}
+	}
+	if *asRSS && feed.Link == "" && feed.SiteURL == "" {
+		return fmt.Errorf("RSS feeds need a channel link, please provide --link for posts that aren't all from one site\n")
+	}
+
+	out, err := createOutput(*outPath)
+	if err != nil {
+		return err
+	}
+	write := syndication.WriteAtom
+	if *asRSS {
+		write = syndication.WriteRSS
+	}
+	err = write(out, feed)
+	closeErr := out.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
+
+func publishedItem(post postquery.Post) syndication.Item {
+	id := itemID(post)
+	return syndication.Item{
+		ID:          id,
+		IsPermaLink: id == post.Url,
+		Title:       post.Title,
+		Link:        post.Url,
+		Summary:     post.Description,
+		Content:     post.Content,
+		Author:      post.Author,
+		Categories:  post.Categories,
+		Published:   post.PublishedAt,
+		Source: syndication.Source{
+			Title:   post.FeedName,
+			URL:     post.FeedUrl,
+			SiteURL: post.FeedSiteUrl,
+		},
+	}
+}
+
+// itemID keeps the id the source feed gave the post when it's usable as an
+// Atom id, falling back to the post's link, which gator keeps unique.
+func itemID(post postquery.Post) string {
+	for _, id := range []string{post.Guid, post.Url} {
+		parsed, err := url.Parse(id)
+		if err == nil && parsed.IsAbs() {
+			return id
+		}
+	}
+	return "urn:uuid:" + post.ID.String()
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jdwalkerzhere/gator/internal/database"
+	"github.com/jdwalkerzhere/gator/internal/postquery"
+)
+
+func handlerRead(s *state, cmd command) error {
+	return setPostsRead(s, cmd, true)
+}
+
+func handlerUnread(s *state, cmd command) error {
+	return setPostsRead(s, cmd, false)
+}
+
+func setPostsRead(s *state, cmd command, read bool) error {
+	flags := newFlagSet(cmd)
+	feedURL := flags.String("feed", "", "mark every post of the feed with this URL")
+	olderThan := flags.String("older-than", "", "mark every post published before a date (YYYY-MM-DD) or age (e.g. 30d)")
+	err := flags.Parse(cmd.args)
+	if err != nil {
+		return err
+	}
+	if *feedURL == "" && *olderThan == "" && flags.NArg() == 0 {
+		return fmt.Errorf("No posts provided, please provide post IDs or URLs, --feed or --older-than\n")
+	}
+	if *feedURL != "" && *olderThan != "" {
+		return fmt.Errorf("Please provide only one of --feed and --older-than\n")
+	}
+
+	user, err := s.db.GetUser(context.Background(), s.cfg.CurrentUser)
+	if err != nil {
+		return err
+	}
+
+	readAt := sql.NullTime{Time: time.Now(), Valid: read}
+	readState := "unread"
+	if read {
+		readState = "read"
+	}
+
+	for _, ref := range flags.Args() {
+		post, err := findPost(context.Background(), s, ref)
+		if err != nil {
+			return err
+		}
+		readParams := database.SetPostReadParams{
+			UserID: user.ID,
+			PostID: post.ID,
+			Read:   read,
+			ReadAt: readAt,
+		}
+		err = s.db.SetPostRead(context.Background(), readParams)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Marked [%s] %s\n", post.Title, readState)
+	}
+
+	if *feedURL != "" {
+		feed, err := s.db.GetFeed(context.Background(), *feedURL)
+		if err != nil {
+			return err
+		}
+		readParams := database.SetFeedPostsReadParams{
+			UserID: user.ID,
+			Read:   read,
+			ReadAt: readAt,
+			FeedID: feed.ID,
+		}
+		marked, err := s.db.SetFeedPostsRead(context.Background(), readParams)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Marked %d posts from [%s] %s\n", marked, feed.Name, readState)
+	}
+
+	if *olderThan != "" {
+		cutoff, err := postquery.ParseCutoff(*olderThan)
+		if err != nil {
+			return err
+		}
+		readParams := database.SetPostsReadBeforeParams{
+			UserID:          user.ID,
+			Read:            read,
+			ReadAt:          readAt,
+			PublishedBefore: cutoff,
+		}
+		marked, err := s.db.SetPostsReadBefore(context.Background(), readParams)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Marked %d posts published before %s %s\n", marked, cutoff.Format(time.DateTime), readState)
+	}
+
+	if read {
+		_, err = s.db.RemoveReadFromReadLater(context.Background(), user.ID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findPost resolves a post from either its ID or its URL.
+func findPost(ctx context.Context, s *state, ref string) (database.Post, error) {
+	var post database.Post
+	id, err := uuid.Parse(ref)
+	if err == nil {
+		post, err = s.db.GetPost(ctx, id)
+	} else {
+		post, err = s.db.GetPostByURL(ctx, ref)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return post, fmt.Errorf("No post [%s] found\n", ref)
+	}
+	return post, err
+}
@@ -0,0 +1,321 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jdwalkerzhere/gator/internal/database"
+)
+
+// ruleMatchesShown caps the posts listed per rule by a dry run.
+const ruleMatchesShown = 10
+
+var ruleActions = map[string]string{
+	"read": "mark read",
+	"star": "star",
+	"hide": "hide",
+}
+
+func handlerAddRule(s *state, cmd command) error {
+	flags := newFlagSet(cmd)
+	feedURL := flags.String("feed", "", "only match posts from the feed with this URL")
+	title := flags.String("title", "", "match titles against this regular expression (case insensitive)")
+	description := flags.String("description", "", "match descriptions against this regular expression (case insensitive)")
+	author := flags.String("author", "", "match posts whose author contains this text")
+	category := flags.String("category", "", "match posts in this category")
+	dryRun := flags.Bool("dry-run", false, "show the posts the rule would match without saving it")
+	err := flags.Parse(cmd.args)
+	if err != nil {
+		return err
+	}
+	if flags.NArg() < 1 || ruleActions[flags.Arg(0)] == "" {
+		return fmt.Errorf("No action provided, please provide one of read, star or hide after the rule's flags\n")
+	}
+	if *feedURL == "" && *title == "" && *description == "" && *author == "" && *category == "" {
+		return fmt.Errorf("No conditions provided, please provide at least one of --feed, --title, --description, --author or --category\n")
+	}
+
+	user, err := s.db.GetUser(context.Background(), s.cfg.CurrentUser)
+	if err != nil {
+		return err
+	}
+
+	timeNow := time.Now()
+	ruleParams := database.CreateRuleParams{
+		ID:                 uuid.New(),
+		CreatedAt:          timeNow,
+		UpdatedAt:          timeNow,
+		UserID:             user.ID,
+		Action:             flags.Arg(0),
+		TitlePattern:       sql.NullString{String: *title, Valid: *title != ""},
+		DescriptionPattern: sql.NullString{String: *description, Valid: *description != ""},
+		Author:             sql.NullString{String: *author, Valid: *author != ""},
+		Category:           sql.NullString{String: *category, Valid: *category != ""},
+	}
+	if *feedURL != "" {
+		follow, err := findFeedFollow(context.Background(), s, user, *feedURL)
+		if err != nil {
+			return err
+		}
+		ruleParams.FeedID = uuid.NullUUID{UUID: follow.FeedID, Valid: true}
+	}
+	// patterns are matched by Postgres, so it is also the one to check them
+	for _, pattern := range []sql.NullString{ruleParams.TitlePattern, ruleParams.DescriptionPattern} {
+		if !pattern.Valid {
+			continue
+		}
+		_, err := s.db.CheckRulePattern(context.Background(), pattern.String)
+		if err != nil {
+			return fmt.Errorf("Invalid regular expression [%s]: %v\n", pattern.String, err)
+		}
+	}
+
+	tx, err := s.sqlDB.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	qtx := s.db.WithTx(tx)
+
+	rule, err := qtx.CreateRule(context.Background(), ruleParams)
+	if err != nil {
+		return err
+	}
+	if *dryRun {
+		// the rule only exists inside the transaction, which is rolled back
+		return printRuleMatches(context.Background(), s, qtx, user, uuid.NullUUID{UUID: rule.ID, Valid: true})
+	}
+
+	applyParams := database.ApplyRulesParams{
+		UserID: uuid.NullUUID{UUID: user.ID, Valid: true},
+		RuleID: uuid.NullUUID{UUID: rule.ID, Valid: true},
+	}
+	applied, err := qtx.ApplyRules(context.Background(), applyParams)
+	if err != nil {
+		return err
+	}
+	// posts the rule marks read leave the read-later queue like any other
+	_, err = qtx.RemoveReadFromReadLater(context.Background(), user.ID)
+	if err != nil {
+		return err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Added rule [%s], applied to %d existing posts\n", rule.ID, applied)
+	return nil
+}
+
+func handlerRules(s *state, cmd command) error {
+	if err := cmd.parseNoFlags(); err != nil {
+		return err
+	}
+	user, err := s.db.GetUser(context.Background(), s.cfg.CurrentUser)
+	if err != nil {
+		return err
+	}
+
+	rules, err := s.db.GetRulesForUser(context.Background(), user.ID)
+	if err != nil {
+		return err
+	}
+	records := make([]ruleRecord, len(rules))
+	for i, rule := range rules {
+		records[i] = ruleRecord{
+			ID:                 rule.ID,
+			Action:             rule.Action,
+			FeedURL:            stringPtr(rule.FeedUrl),
+			TitlePattern:       stringPtr(rule.TitlePattern),
+			DescriptionPattern: stringPtr(rule.DescriptionPattern),
+			Author:             stringPtr(rule.Author),
+			Category:           stringPtr(rule.Category),
+		}
+	}
+	return printRecords(s, records, func() {
+		if len(rules) == 0 {
+			fmt.Println("No rules, add one with `gator addrule`")
+		}
+		for i, rule := range rules {
+			fmt.Printf("%d. %s\n\tID: %s\n", i+1, describeRule(rule), rule.ID)
+		}
+	})
+}
+
+// ruleRecord is a rule, with null for the conditions it doesn't have.
+type ruleRecord struct {
+	ID                 uuid.UUID `json:"id"`
+	Action             string    `json:"action"`
+	FeedURL            *string   `json:"feed_url"`
+	TitlePattern       *string   `json:"title_pattern"`
+	DescriptionPattern *string   `json:"description_pattern"`
+	Author             *string   `json:"author"`
+	Category           *string   `json:"category"`
+}
+
+func describeRule(rule database.GetRulesForUserRow) string {
+	var conditions []string
+	if rule.TitlePattern.Valid {
+		conditions = append(conditions, fmt.Sprintf("title matches /%s/", rule.TitlePattern.String))
+	}
+	if rule.DescriptionPattern.Valid {
+		conditions = append(conditions, fmt.Sprintf("description matches /%s/", rule.DescriptionPattern.String))
+	}
+	if rule.Author.Valid {
+		conditions = append(conditions, fmt.Sprintf("author contains [%s]", rule.Author.String))
+	}
+	if rule.Category.Valid {
+		conditions = append(conditions, fmt.Sprintf("category is [%s]", rule.Category.String))
+	}
+
+	description := ruleActions[rule.Action] + " posts"
+	if rule.FeedUrl.Valid {
+		description += fmt.Sprintf(" from [%s]", rule.FeedUrl.String)
+	}
+	if len(conditions) > 0 {
+		description += " where " + strings.Join(conditions, " and ")
+	}
+	return description
+}
+
+func handlerDeleteRule(s *state, cmd command) error {
+	if err := cmd.parseNoFlags(); err != nil {
+		return err
+	}
+	if len(cmd.args) < 1 {
+		return fmt.Errorf("No rule ID provided, please provide one from `gator rules`\n")
+	}
+	ruleID, err := uuid.Parse(cmd.args[0])
+	if err != nil {
+		return fmt.Errorf("Invalid rule ID [%s]\n", cmd.args[0])
+	}
+
+	user, err := s.db.GetUser(context.Background(), s.cfg.CurrentUser)
+	if err != nil {
+		return err
+	}
+
+	deleteParams := database.DeleteRuleParams{
+		UserID: user.ID,
+		ID:     ruleID,
+	}
+	deleted, err := s.db.DeleteRule(context.Background(), deleteParams)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return fmt.Errorf("No rule [%s] exists\n", cmd.args[0])
+	}
+	fmt.Printf("Deleted rule [%s], posts it already matched keep their state\n", cmd.args[0])
+	return nil
+}
+
+func handlerApplyRules(s *state, cmd command) error {
+	flags := newFlagSet(cmd)
+	dryRun := flags.Bool("dry-run", false, "show the posts the rules match without changing them")
+	err := flags.Parse(cmd.args)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.db.GetUser(context.Background(), s.cfg.CurrentUser)
+	if err != nil {
+		return err
+	}
+
+	ruleID := uuid.NullUUID{}
+	if flags.NArg() > 0 {
+		ruleID.UUID, err = uuid.Parse(flags.Arg(0))
+		if err != nil {
+			return fmt.Errorf("Invalid rule ID [%s]\n", flags.Arg(0))
+		}
+		ruleID.Valid = true
+	}
+	if *dryRun {
+		return printRuleMatches(context.Background(), s, s.db, user, ruleID)
+	}
+
+	applyParams := database.ApplyRulesParams{
+		UserID: uuid.NullUUID{UUID: user.ID, Valid: true},
+		RuleID: ruleID,
+	}
+	tx, err := s.sqlDB.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	qtx := s.db.WithTx(tx)
+
+	applied, err := qtx.ApplyRules(context.Background(), applyParams)
+	if err != nil {
+		return err
+	}
+	_, err = qtx.RemoveReadFromReadLater(context.Background(), user.ID)
+	if err != nil {
+		return err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Rules applied to %d posts\n", applied)
+	return nil
+}
+
+// ruleMatchRecord is a post a rule would act on if applied now.
+type ruleMatchRecord struct {
+	RuleID      uuid.UUID `json:"rule_id"`
+	Action      string    `json:"action"`
+	PostID      uuid.UUID `json:"post_id"`
+	Title       string    `json:"title"`
+	URL         string    `json:"url"`
+	Feed        string    `json:"feed"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+func printRuleMatches(ctx context.Context, s *state, db *database.Queries, user database.User, ruleID uuid.NullUUID) error {
+	matchParams := database.GetRuleMatchesParams{
+		UserID: user.ID,
+		RuleID: ruleID,
+	}
+	matches, err := db.GetRuleMatches(ctx, matchParams)
+	if err != nil {
+		return err
+	}
+	records := make([]ruleMatchRecord, len(matches))
+	for i, match := range matches {
+		records[i] = ruleMatchRecord{
+			RuleID:      match.RuleID,
+			Action:      match.Action,
+			PostID:      match.ID,
+			Title:       match.Title,
+			URL:         match.Url,
+			Feed:        match.FeedName,
+			PublishedAt: match.PublishedAt,
+		}
+	}
+	return printRecords(s, records, func() {
+		if len(records) == 0 {
+			fmt.Println("No posts match")
+			return
+		}
+		for start := 0; start < len(records); {
+			end := start
+			for end < len(records) && records[end].RuleID == records[start].RuleID {
+				end++
+			}
+			fmt.Printf("Rule [%s] would %s %d posts:\n", records[start].RuleID, ruleActions[records[start].Action], end-start)
+			for _, record := range records[start:min(end, start+ruleMatchesShown)] {
+				fmt.Printf("\t* %s (%s, %s)\n", record.Title, record.Feed, record.PublishedAt.Format(time.DateOnly))
+			}
+			if end-start > ruleMatchesShown {
+				fmt.Printf("\t... and %d more\n", end-start-ruleMatchesShown)
+			}
+			start = end
+		}
+	})
+}
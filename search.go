@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jdwalkerzhere/gator/internal/database"
+	"github.com/jdwalkerzhere/gator/internal/markup"
+	"github.com/jdwalkerzhere/gator/internal/postquery"
+)
+
+// searchConfigs maps the language codes feeds declare to the text search
+// configurations Postgres ships with.
+var searchConfigs = map[string]string{
+	"ar": "arabic",
+	"da": "danish",
+	"de": "german",
+	"el": "greek",
+	"en": "english",
+	"es": "spanish",
+	"fi": "finnish",
+	"fr": "french",
+	"ga": "irish",
+	"hu": "hungarian",
+	"id": "indonesian",
+	"it": "italian",
+	"lt": "lithuanian",
+	"nb": "norwegian",
+	"ne": "nepali",
+	"nl": "dutch",
+	"nn": "norwegian",
+	"no": "norwegian",
+	"pt": "portuguese",
+	"ro": "romanian",
+	"ru": "russian",
+	"sv": "swedish",
+	"ta": "tamil",
+	"tr": "turkish",
+}
+
+func searchConfigFor(language string) string {
+	code, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(language)), "-")
+	code, _, _ = strings.Cut(code, "_")
+	return searchConfigs[code]
+}
+
+// languageCode is the language code searchConfigs maps to a configuration,
+// or "" for configurations such as simple that aren't a language.
+func languageCode(config string) string {
+	code := ""
+	for candidate, name := range searchConfigs {
+		if name == config && (code == "" || candidate < code) {
+			code = candidate
+		}
+	}
+	return code
+}
+
+func handlerSearch(s *state, cmd command) error {
+	flags := newFlagSet(cmd)
+	options := addBrowseFlags(flags.FlagSet, true)
+	limit := flags.Int("limit", 10, "show at most this many results")
+	err := flags.Parse(cmd.args)
+	if err != nil {
+		return err
+	}
+	*options.query = strings.TrimSpace(*options.query + " " + postquery.JoinArgs(flags.Args()))
+	if *options.query == "" && *options.search == "" {
+		return fmt.Errorf("No query provided, please provide words to search for and any of\n\t%s\n", strings.Join(postquery.Fields(), "\n\t"))
+	}
+	if *options.cursor != "" {
+		return fmt.Errorf("Search results are ranked, use --offset instead of --cursor to page through them\n")
+	}
+
+	user, err := s.db.GetUser(context.Background(), s.cfg.CurrentUser)
+	if err != nil {
+		return err
+	}
+	filter, err := options.filter(context.Background(), s, user, int32(*limit))
+	if err != nil {
+		return err
+	}
+	filter.ByRank = true
+	// matches are marked in bold on a terminal, between asterisks elsewhere
+	// and not at all for scripts
+	styled := s.output == outputText && isTerminal(os.Stdout)
+	switch {
+	case styled:
+		filter.Highlight = [2]string{"<b>", "</b>"}
+	case s.output == outputText:
+		filter.Highlight = [2]string{"*", "*"}
+	}
+
+	posts, err := postquery.List(context.Background(), s.sqlDB, filter)
+	if err != nil {
+		return err
+	}
+	records := make([]searchRecord, len(posts))
+	for i, post := range posts {
+		// without search words there is nothing to highlight
+		match := plainText(post.Snippet, styled)
+		if match == "" {
+			match = plainText(post.Description, styled)
+		}
+		records[i] = searchRecord{
+			Rank:        int(filter.Offset) + i + 1,
+			ID:          post.ID,
+			Title:       post.Title,
+			URL:         post.Url,
+			Feed:        post.FeedName,
+			FeedURL:     post.FeedUrl,
+			PublishedAt: post.PublishedAt,
+			Read:        post.Read,
+			Starred:     post.Starred,
+			Match:       match,
+		}
+	}
+	err = printRecords(s, records, func() {
+		if len(records) == 0 {
+			fmt.Println("No posts match the search")
+		}
+		for _, post := range records {
+			title := post.Title
+			if post.Read {
+				title += " (read)"
+			}
+			if post.Starred {
+				title += " (starred)"
+			}
+			fmt.Printf("%d. %s\n\tID: %s\n\tFeed: %s\n\tPublished: %s\n\tMatch: %s\n\tLink: %s\n\n",
+				post.Rank, title, post.ID, post.Feed, post.PublishedAt.Format(time.DateTime), post.Match, post.URL)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if len(posts) > 0 && len(posts) == *limit {
+		// keep the hint out of the way of scripts reading the results
+		hint := os.Stdout
+		if s.output != outputText {
+			hint = os.Stderr
+		}
+		fmt.Fprintf(hint, "More results: --offset %d\n", int(filter.Offset)+len(posts))
+	}
+	return nil
+}
+
+type searchRecord struct {
+	Rank        int       `json:"rank"`
+	ID          uuid.UUID `json:"id"`
+	Title       string    `json:"title"`
+	URL         string    `json:"url"`
+	Feed        string    `json:"feed"`
+	FeedURL     string    `json:"feed_url"`
+	PublishedAt time.Time `json:"published_at"`
+	Read        bool      `json:"read"`
+	Starred     bool      `json:"starred"`
+	Match       string    `json:"match"`
+}
+
+func handlerLanguage(s *state, cmd command) error {
+	if err := cmd.parseNoFlags(); err != nil {
+		return err
+	}
+	if len(cmd.args) < 1 {
+		return fmt.Errorf("No URL provided, please provide the feed to set the language of\n")
+	}
+	feed, err := s.db.GetFeed(context.Background(), cmd.args[0])
+	if err != nil {
+		return err
+	}
+	if len(cmd.args) < 2 {
+		language := "simple (not detected yet)"
+		if feed.Language.Valid {
+			language = feed.Language.String
+		}
+		fmt.Printf("Feed [%s] is searched as [%s]\n", feed.Name, language)
+		return nil
+	}
+
+	// "auto" goes back to whatever the feed declares on its next fetch
+	language := sql.NullString{String: cmd.args[1], Valid: cmd.args[1] != "auto"}
+	if language.Valid {
+		exists, err := s.db.TextSearchConfigExists(context.Background(), language.String)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("No text search configuration [%s], see `\\dF` in psql for the available ones\n", language.String)
+		}
+	}
+	err = s.db.SetFeedLanguage(context.Background(), database.SetFeedLanguageParams{ID: feed.ID, Language: language})
+	if err != nil {
+		return err
+	}
+	reindexed, err := s.db.ReindexFeedSearch(context.Background(), feed.ID)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Feed [%s] language set to [%s], %d posts reindexed\n", feed.Name, cmd.args[1], reindexed)
+	return nil
+}
+
+// plainText flattens an HTML fragment to a single line of text, styled with
+// ANSI escape codes if styled is set.
+func plainText(fragment string, styled bool) string {
+	text := markup.Text(fragment, markup.TextOptions{Styled: styled, NoFootnotes: true})
+	return strings.Join(strings.Fields(text), " ")
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
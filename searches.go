@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jdwalkerzhere/gator/internal/database"
+	"github.com/jdwalkerzhere/gator/internal/postquery"
+)
+
+func handlerSaveSearch(s *state, cmd command) error {
+	if err := cmd.parseNoFlags(); err != nil {
+		return err
+	}
+	if len(cmd.args) < 2 {
+		return fmt.Errorf("Insufficient arguments, please provide both a name and the query to save\n")
+	}
+	query := postquery.JoinArgs(cmd.args[1:])
+	_, err := postquery.Parse(query)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.db.GetUser(context.Background(), s.cfg.CurrentUser)
+	if err != nil {
+		return err
+	}
+
+	timeNow := time.Now()
+	searchParams := database.UpsertSavedSearchParams{
+		ID:        uuid.New(),
+		CreatedAt: timeNow,
+		UpdatedAt: timeNow,
+		UserID:    user.ID,
+		Name:      cmd.args[0],
+		Query:     query,
+	}
+	saved, err := s.db.UpsertSavedSearch(context.Background(), searchParams)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Saved search [%s]: %s\n", saved.Name, saved.Query)
+	return nil
+}
+
+func handlerSearches(s *state, cmd command) error {
+	if err := cmd.parseNoFlags(); err != nil {
+		return err
+	}
+	user, err := s.db.GetUser(context.Background(), s.cfg.CurrentUser)
+	if err != nil {
+		return err
+	}
+
+	searches, err := s.db.GetSavedSearchesForUser(context.Background(), user.ID)
+	if err != nil {
+		return err
+	}
+	records := make([]savedSearchRecord, len(searches))
+	for i, search := range searches {
+		records[i] = savedSearchRecord{Name: search.Name, Query: search.Query}
+	}
+	return printRecords(s, records, func() {
+		if len(records) == 0 {
+			fmt.Println("No saved searches, save one with `gator savesearch <name> <query>`")
+		}
+		for _, search := range records {
+			fmt.Printf("* %s: %s\n", search.Name, search.Query)
+		}
+	})
+}
+
+type savedSearchRecord struct {
+	Name  string `json:"name"`
+	Query string `json:"query"`
+}
+
+func handlerRemoveSearch(s *state, cmd command) error {
+	if err := cmd.parseNoFlags(); err != nil {
+		return err
+	}
+	if len(cmd.args) < 1 {
+		return fmt.Errorf("No search name provided, please provide one\n")
+	}
+
+	user, err := s.db.GetUser(context.Background(), s.cfg.CurrentUser)
+	if err != nil {
+		return err
+	}
+
+	deleteParams := database.DeleteSavedSearchParams{
+		UserID: user.ID,
+		Name:   cmd.args[0],
+	}
+	deleted, err := s.db.DeleteSavedSearch(context.Background(), deleteParams)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return fmt.Errorf("No saved search [%s] exists\n", cmd.args[0])
+	}
+	fmt.Printf("Deleted saved search [%s]\n", cmd.args[0])
+	return nil
+}
+
+func findSavedSearch(ctx context.Context, s *state, user database.User, name string) (database.SavedSearch, error) {
+	searchParams := database.GetSavedSearchParams{
+		UserID: user.ID,
+		Name:   name,
+	}
+	search, err := s.db.GetSavedSearch(ctx, searchParams)
+	if errors.Is(err, sql.ErrNoRows) {
+		return search, fmt.Errorf("No saved search [%s] exists\n", name)
+	}
+	return search, err
+}
@@ -0,0 +1,228 @@
+-- name: DumpUsers :many
+SELECT * FROM users
+ORDER BY created_at, id;
+
+-- name: DumpFeeds :many
+SELECT * FROM feeds
+ORDER BY created_at, id;
+
+-- name: DumpFeedFollows :many
+SELECT * FROM feed_follows
+ORDER BY created_at, id;
+
+-- name: DumpFolders :many
+SELECT * FROM folders
+ORDER BY created_at, id;
+
+-- name: DumpFolderFeeds :many
+SELECT * FROM folder_feeds
+ORDER BY created_at, folder_id, feed_follow_id;
+
+-- name: DumpPosts :many
+SELECT * FROM posts
+ORDER BY published_at, id;
+
+-- name: DumpPostStates :many
+SELECT * FROM post_states
+ORDER BY created_at, user_id, post_id;
+
+-- name: DumpReadLater :many
+SELECT * FROM read_later
+ORDER BY user_id, position;
+
+-- name: DumpSavedSearches :many
+SELECT * FROM saved_searches
+ORDER BY created_at, id;
+
+-- name: DumpRules :many
+SELECT * FROM rules
+ORDER BY created_at, id;
+
+-- name: DumpPostTombstones :many
+SELECT * FROM post_tombstones
+ORDER BY pruned_at, feed_id, guid;
+
+-- name: RestoreUser :one
+INSERT INTO users (id, created_at, updated_at, name)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4
+)
+ON CONFLICT (name) DO UPDATE
+SET name = users.name
+RETURNING id;
+
+-- name: RestoreFeed :one
+INSERT INTO feeds (id, created_at, updated_at, name, url, user_id, last_fetched_at, hub_url, hub_topic, language, retention_days, retention_posts, site_url)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8,
+    $9,
+    $10,
+    $11,
+    $12,
+    $13
+)
+ON CONFLICT (url) DO UPDATE
+SET url = feeds.url
+RETURNING id;
+
+-- name: RestoreFeedFollow :one
+INSERT INTO feed_follows (id, created_at, updated_at, user_id, feed_id)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5
+)
+ON CONFLICT (user_id, feed_id) DO UPDATE
+SET user_id = feed_follows.user_id
+RETURNING id;
+
+-- name: RestoreFolder :one
+INSERT INTO folders (id, created_at, updated_at, user_id, name)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5
+)
+ON CONFLICT (user_id, name) DO UPDATE
+SET name = folders.name
+RETURNING id;
+
+-- name: RestoreFolderFeed :exec
+INSERT INTO folder_feeds (folder_id, feed_follow_id, created_at)
+VALUES (
+    $1,
+    $2,
+    $3
+)
+ON CONFLICT (folder_id, feed_follow_id) DO NOTHING;
+
+-- name: RestorePost :one
+INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id, author, categories, content, guid)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8,
+    $9,
+    $10,
+    $11,
+    $12
+)
+ON CONFLICT (url) DO UPDATE
+SET url = posts.url
+RETURNING id;
+
+-- name: RestorePostState :execrows
+INSERT INTO post_states (user_id, post_id, created_at, updated_at, read, read_at, starred, starred_at, hidden)
+VALUES (
+    sqlc.arg(user_id),
+    sqlc.arg(post_id),
+    sqlc.arg(created_at),
+    sqlc.arg(updated_at),
+    sqlc.arg(read),
+    sqlc.narg(read_at),
+    sqlc.arg(starred),
+    sqlc.narg(starred_at),
+    sqlc.arg(hidden)
+)
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET
+    (updated_at, read, read_at, starred, starred_at, hidden) = (EXCLUDED.updated_at, EXCLUDED.read, EXCLUDED.read_at, EXCLUDED.starred, EXCLUDED.starred_at, EXCLUDED.hidden)
+WHERE sqlc.arg(replace)::boolean;
+
+-- name: RestoreReadLater :execrows
+INSERT INTO read_later (user_id, post_id, created_at, position)
+VALUES (
+    sqlc.arg(user_id),
+    sqlc.arg(post_id),
+    sqlc.arg(created_at),
+    sqlc.arg(position)
+)
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET position = EXCLUDED.position
+WHERE sqlc.arg(replace)::boolean;
+
+-- name: RestoreSavedSearch :execrows
+INSERT INTO saved_searches (id, created_at, updated_at, user_id, name, query)
+VALUES (
+    sqlc.arg(id),
+    sqlc.arg(created_at),
+    sqlc.arg(updated_at),
+    sqlc.arg(user_id),
+    sqlc.arg(name),
+    sqlc.arg(query)
+)
+ON CONFLICT (user_id, name) DO UPDATE
+SET (updated_at, query) = (EXCLUDED.updated_at, EXCLUDED.query)
+WHERE sqlc.arg(replace)::boolean;
+
+-- name: RestoreRule :execrows
+-- a rule is matched on what it does rather than its id, so merging an
+-- archive doesn't add a second copy of a rule the user already has
+INSERT INTO rules (id, created_at, updated_at, user_id, action, feed_id, title_pattern, description_pattern, author, category)
+SELECT
+    sqlc.arg(id)::uuid,
+    sqlc.arg(created_at)::timestamp,
+    sqlc.arg(updated_at)::timestamp,
+    sqlc.arg(user_id)::uuid,
+    sqlc.arg(action)::text,
+    sqlc.narg(feed_id)::uuid,
+    sqlc.narg(title_pattern)::text,
+    sqlc.narg(description_pattern)::text,
+    sqlc.narg(author)::text,
+    sqlc.narg(category)::text
+WHERE NOT EXISTS (
+    SELECT 1
+    FROM rules
+    WHERE rules.user_id = sqlc.arg(user_id)::uuid
+    AND rules.action = sqlc.arg(action)::text
+    AND rules.feed_id IS NOT DISTINCT FROM sqlc.narg(feed_id)::uuid
+    AND rules.title_pattern IS NOT DISTINCT FROM sqlc.narg(title_pattern)::text
+    AND rules.description_pattern IS NOT DISTINCT FROM sqlc.narg(description_pattern)::text
+    AND rules.author IS NOT DISTINCT FROM sqlc.narg(author)::text
+    AND rules.category IS NOT DISTINCT FROM sqlc.narg(category)::text
+)
+ON CONFLICT (id) DO UPDATE
+SET
+    (updated_at, action, feed_id, title_pattern, description_pattern, author, category) = (EXCLUDED.updated_at, EXCLUDED.action, EXCLUDED.feed_id, EXCLUDED.title_pattern, EXCLUDED.description_pattern, EXCLUDED.author, EXCLUDED.category)
+WHERE sqlc.arg(replace)::boolean AND rules.user_id = EXCLUDED.user_id;
+
+-- name: RestorePostTombstone :exec
+INSERT INTO post_tombstones (feed_id, guid, pruned_at)
+VALUES (
+    $1,
+    $2,
+    $3
+)
+ON CONFLICT (feed_id, guid) DO NOTHING;
+
+-- name: RenumberReadLater :exec
+-- merging queues can leave two posts at one position, so positions are
+-- counted again from 1 in the order they sort in
+UPDATE read_later
+SET position = renumbered.position
+FROM (
+    SELECT user_id, post_id, row_number() OVER (PARTITION BY user_id ORDER BY position, created_at, post_id)::int AS position
+    FROM read_later
+) renumbered
+WHERE read_later.user_id = renumbered.user_id
+AND read_later.post_id = renumbered.post_id
+AND read_later.position <> renumbered.position;
@@ -28,3 +28,14 @@ WHERE u.id = $1;
 -- name: Unfollow :exec
 DELETE FROM feed_follows
 WHERE user_id = $1 AND feed_id = $2;
+
+-- name: IsFollowingFeed :one
+SELECT EXISTS (
+    SELECT 1
+    FROM feed_follows
+    WHERE user_id = $1 AND feed_id = $2
+);
+
+-- name: GetFeedFollow :one
+SELECT * FROM feed_follows
+WHERE user_id = $1 AND feed_id = $2;
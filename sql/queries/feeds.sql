@@ -33,3 +33,41 @@ SELECT *
 FROM feeds
 ORDER BY last_fetched_at NULLS FIRST, updated_at
 LIMIT 1;
+
+-- name: GetFeedByID :one
+SELECT * FROM feeds
+WHERE id = $1;
+
+-- name: SetFeedHub :exec
+UPDATE feeds
+SET
+    (hub_url, hub_topic) = ($2, $3)
+WHERE id = $1;
+
+-- name: GetFeedsWithHub :many
+SELECT *
+FROM feeds
+WHERE hub_url IS NOT NULL;
+
+-- name: GetFeedFetchQueue :many
+SELECT *
+FROM feeds
+ORDER BY last_fetched_at NULLS FIRST, updated_at;
+
+-- name: SetFeedLanguage :exec
+UPDATE feeds
+SET
+    (updated_at, language) = (NOW(), $2)
+WHERE id = $1;
+
+-- name: DetectFeedLanguage :execrows
+UPDATE feeds
+SET
+    language = $2
+WHERE id = $1 AND language IS NULL;
+
+-- name: SetFeedSiteURL :exec
+UPDATE feeds
+SET
+    site_url = $2
+WHERE id = $1;
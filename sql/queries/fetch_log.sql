@@ -0,0 +1,24 @@
+-- name: CreateFetchLog :exec
+INSERT INTO fetch_log (id, feed_id, started_at, finished_at, http_status, bytes, items_seen, new_posts, error)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8,
+    $9
+);
+
+-- name: GetFetchLogForFeed :many
+SELECT *
+FROM fetch_log
+WHERE feed_id = $1
+ORDER BY started_at DESC
+LIMIT $2;
+
+-- name: PruneFetchLog :execrows
+DELETE FROM fetch_log
+WHERE started_at < $1;
@@ -0,0 +1,50 @@
+-- name: UpsertFolder :one
+INSERT INTO folders (id, created_at, updated_at, user_id, name)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5
+)
+ON CONFLICT (user_id, name) DO UPDATE
+SET updated_at = folders.updated_at
+RETURNING *;
+
+-- name: GetFolder :one
+SELECT * FROM folders
+WHERE user_id = $1 AND name = $2;
+
+-- name: GetFoldersForUser :many
+SELECT folders.*, COUNT(folder_feeds.feed_follow_id) AS feed_count
+FROM folders
+LEFT JOIN folder_feeds ON folder_feeds.folder_id = folders.id
+WHERE folders.user_id = $1
+GROUP BY folders.id
+ORDER BY folders.name;
+
+-- name: DeleteFolder :execrows
+DELETE FROM folders
+WHERE user_id = $1 AND name = $2;
+
+-- name: AddFollowToFolder :execrows
+INSERT INTO folder_feeds (folder_id, feed_follow_id, created_at)
+VALUES (
+    $1,
+    $2,
+    $3
+)
+ON CONFLICT (folder_id, feed_follow_id) DO NOTHING;
+
+-- name: RemoveFollowFromFolder :execrows
+DELETE FROM folder_feeds
+WHERE folder_id = $1 AND feed_follow_id = $2;
+
+-- name: GetFollowingByFolder :many
+SELECT f.name AS feed_name, f.url, f.site_url, folders.name AS folder_name
+FROM feed_follows ff
+INNER JOIN feeds f ON f.id = ff.feed_id
+LEFT JOIN folder_feeds ON folder_feeds.feed_follow_id = ff.id
+LEFT JOIN folders ON folders.id = folder_feeds.folder_id
+WHERE ff.user_id = $1
+ORDER BY folders.name NULLS LAST, f.name;
@@ -0,0 +1,58 @@
+-- name: SetPostRead :exec
+INSERT INTO post_states (user_id, post_id, created_at, updated_at, read, read_at)
+VALUES (
+    $1,
+    $2,
+    NOW(),
+    NOW(),
+    $3,
+    $4
+)
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET
+    (updated_at, read, read_at) = (NOW(), EXCLUDED.read, EXCLUDED.read_at);
+
+-- name: SetFeedPostsRead :execrows
+INSERT INTO post_states (user_id, post_id, created_at, updated_at, read, read_at)
+SELECT sqlc.arg(user_id)::uuid, posts.id, NOW(), NOW(), sqlc.arg(read)::boolean, sqlc.narg(read_at)::timestamp
+FROM posts
+WHERE posts.feed_id = sqlc.arg(feed_id)
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET
+    (updated_at, read, read_at) = (NOW(), EXCLUDED.read, EXCLUDED.read_at);
+
+-- name: SetPostsReadBefore :execrows
+INSERT INTO post_states (user_id, post_id, created_at, updated_at, read, read_at)
+SELECT sqlc.arg(user_id)::uuid, posts.id, NOW(), NOW(), sqlc.arg(read)::boolean, sqlc.narg(read_at)::timestamp
+FROM posts
+WHERE posts.published_at < sqlc.arg(published_before)
+AND posts.feed_id IN (
+    SELECT feed_id
+    FROM feed_follows
+    WHERE feed_follows.user_id = sqlc.arg(user_id)::uuid
+)
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET
+    (updated_at, read, read_at) = (NOW(), EXCLUDED.read, EXCLUDED.read_at);
+
+-- name: SetPostStarred :exec
+INSERT INTO post_states (user_id, post_id, created_at, updated_at, starred, starred_at)
+VALUES (
+    $1,
+    $2,
+    NOW(),
+    NOW(),
+    $3,
+    $4
+)
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET
+    (updated_at, starred, starred_at) = (NOW(), EXCLUDED.starred, EXCLUDED.starred_at);
+
+-- name: GetStarredPostsForUser :many
+SELECT posts.*, post_states.starred_at
+FROM posts
+INNER JOIN post_states ON post_states.post_id = posts.id
+WHERE post_states.user_id = $1 AND post_states.starred
+ORDER BY post_states.starred_at DESC
+LIMIT $2;
@@ -1,5 +1,5 @@
 -- name: CreatePost :one
-INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id)
+INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id, author, categories, content, guid)
 VALUES (
     $1,
     $2,
@@ -8,17 +8,21 @@ VALUES (
     $5,
     $6,
     $7,
-    $8
+    $8,
+    $9,
+    $10,
+    $11,
+    $12
 )
 RETURNING *;
 
--- name: GetPostsForUser :many
-with followed_feeds AS (
-    SELECT feed_id
-    FROM feed_follows
-    WHERE user_id = $1
-)
-SELECT posts.* FROM posts
-WHERE feed_id IN (SELECT feed_id FROM followed_feeds)
-ORDER BY published_at DESC
-LIMIT $2;
+-- name: GetPost :one
+SELECT * FROM posts
+WHERE id = $1;
+
+-- name: GetPostByURL :one
+SELECT * FROM posts
+WHERE url = $1;
+
+-- name: NotifyNewPosts :exec
+SELECT pg_notify('gator_posts', sqlc.arg(feed_id)::uuid::text);
@@ -0,0 +1,44 @@
+-- name: AddToReadLater :execrows
+INSERT INTO read_later (user_id, post_id, created_at, position)
+VALUES (
+    $1,
+    $2,
+    $3,
+    (SELECT COALESCE(MAX(position), 0) + 1 FROM read_later WHERE user_id = $1)
+)
+ON CONFLICT (user_id, post_id) DO NOTHING;
+
+-- name: GetReadLaterQueue :many
+SELECT posts.*, read_later.position
+FROM read_later
+INNER JOIN posts ON posts.id = read_later.post_id
+WHERE read_later.user_id = $1
+ORDER BY read_later.position;
+
+-- name: SetReadLaterPosition :exec
+UPDATE read_later
+SET position = $3
+WHERE user_id = $1 AND post_id = $2;
+
+-- name: RemoveFromReadLater :execrows
+DELETE FROM read_later
+WHERE user_id = $1 AND post_id = $2;
+
+-- name: PopReadLater :one
+DELETE FROM read_later
+WHERE read_later.user_id = $1 AND read_later.post_id = (
+    SELECT next.post_id
+    FROM read_later next
+    WHERE next.user_id = $1
+    ORDER BY next.position
+    LIMIT 1
+)
+RETURNING post_id;
+
+-- name: RemoveReadFromReadLater :execrows
+DELETE FROM read_later
+USING post_states
+WHERE read_later.user_id = $1
+AND post_states.user_id = read_later.user_id
+AND post_states.post_id = read_later.post_id
+AND post_states.read;
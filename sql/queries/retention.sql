@@ -0,0 +1,88 @@
+-- name: SetFeedRetention :exec
+UPDATE feeds
+SET
+    (updated_at, retention_days, retention_posts) = (NOW(), $2, $3)
+WHERE id = $1;
+
+-- name: GetFeedsWithRetention :many
+SELECT name, url, retention_days, retention_posts
+FROM feeds
+WHERE retention_days IS NOT NULL OR retention_posts IS NOT NULL
+ORDER BY name;
+
+-- name: IsPostTombstoned :one
+SELECT EXISTS (
+    SELECT 1
+    FROM post_tombstones
+    WHERE feed_id = $1 AND guid = $2
+);
+
+-- name: CountPrunablePosts :many
+WITH ranked AS (
+    SELECT
+        posts.id,
+        posts.feed_id,
+        posts.published_at,
+        row_number() OVER (PARTITION BY posts.feed_id ORDER BY posts.published_at DESC, posts.id DESC) AS position,
+        COALESCE(feeds.retention_days, default_retention.days) AS max_days,
+        COALESCE(feeds.retention_posts, default_retention.posts) AS max_posts
+    FROM posts
+    INNER JOIN feeds ON feeds.id = posts.feed_id
+    CROSS JOIN default_retention
+)
+SELECT feeds.name, feeds.url, COUNT(ranked.id) AS prunable
+FROM ranked
+INNER JOIN feeds ON feeds.id = ranked.feed_id
+WHERE (
+    (ranked.max_days > 0 AND ranked.published_at < NOW() - make_interval(days => ranked.max_days))
+    OR (ranked.max_posts > 0 AND ranked.position > ranked.max_posts)
+)
+AND NOT EXISTS (SELECT 1 FROM post_states WHERE post_states.post_id = ranked.id AND post_states.starred)
+AND NOT EXISTS (SELECT 1 FROM read_later WHERE read_later.post_id = ranked.id)
+GROUP BY feeds.id
+ORDER BY feeds.name;
+
+-- name: PrunePosts :one
+WITH ranked AS (
+    SELECT
+        posts.id,
+        posts.published_at,
+        row_number() OVER (PARTITION BY posts.feed_id ORDER BY posts.published_at DESC, posts.id DESC) AS position,
+        COALESCE(feeds.retention_days, default_retention.days) AS max_days,
+        COALESCE(feeds.retention_posts, default_retention.posts) AS max_posts
+    FROM posts
+    INNER JOIN feeds ON feeds.id = posts.feed_id
+    CROSS JOIN default_retention
+), pruned AS (
+    DELETE FROM posts
+    WHERE posts.id IN (
+        SELECT ranked.id
+        FROM ranked
+        WHERE (
+            (ranked.max_days > 0 AND ranked.published_at < NOW() - make_interval(days => ranked.max_days))
+            OR (ranked.max_posts > 0 AND ranked.position > ranked.max_posts)
+        )
+        AND NOT EXISTS (SELECT 1 FROM post_states WHERE post_states.post_id = ranked.id AND post_states.starred)
+        AND NOT EXISTS (SELECT 1 FROM read_later WHERE read_later.post_id = ranked.id)
+    )
+    RETURNING posts.feed_id, posts.guid, posts.url
+), tombstoned AS (
+    INSERT INTO post_tombstones (feed_id, guid, pruned_at)
+    SELECT pruned.feed_id, COALESCE(NULLIF(pruned.guid, ''), pruned.url), NOW()
+    FROM pruned
+    ON CONFLICT (feed_id, guid) DO NOTHING
+)
+SELECT count(*) FROM pruned;
+
+-- name: PruneTombstones :execrows
+DELETE FROM post_tombstones
+WHERE pruned_at < $1;
+
+-- name: GetDefaultRetention :one
+SELECT days, posts
+FROM default_retention;
+
+-- name: SetDefaultRetention :exec
+UPDATE default_retention
+SET
+    (updated_at, days, posts) = (NOW(), $1, $2);
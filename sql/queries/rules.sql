@@ -0,0 +1,72 @@
+-- name: CreateRule :one
+INSERT INTO rules (id, created_at, updated_at, user_id, action, feed_id, title_pattern, description_pattern, author, category)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8,
+    $9,
+    $10
+)
+RETURNING *;
+
+-- name: GetRulesForUser :many
+SELECT rules.*, feeds.url AS feed_url
+FROM rules
+LEFT JOIN feeds ON feeds.id = rules.feed_id
+WHERE rules.user_id = $1
+ORDER BY rules.created_at;
+
+-- name: DeleteRule :execrows
+DELETE FROM rules
+WHERE user_id = $1 AND id = $2;
+
+-- name: CheckRulePattern :one
+SELECT '' ~* sqlc.arg(pattern)::text AS matches_empty;
+
+-- name: GetRuleMatches :many
+SELECT rule_matches.rule_id, rule_matches.action, posts.id, posts.title, posts.url, posts.published_at, feeds.name AS feed_name
+FROM rule_matches
+INNER JOIN posts ON posts.id = rule_matches.post_id
+INNER JOIN feeds ON feeds.id = posts.feed_id
+WHERE rule_matches.user_id = sqlc.arg(user_id)
+AND (sqlc.narg(rule_id)::uuid IS NULL OR rule_matches.rule_id = sqlc.narg(rule_id)::uuid)
+ORDER BY rule_matches.rule_id, posts.published_at DESC;
+
+-- name: ApplyRules :execrows
+INSERT INTO post_states (user_id, post_id, created_at, updated_at, read, read_at, starred, starred_at, hidden)
+SELECT
+    matches.user_id,
+    matches.post_id,
+    NOW(),
+    NOW(),
+    matches.read,
+    CASE WHEN matches.read THEN NOW() END,
+    matches.starred,
+    CASE WHEN matches.starred THEN NOW() END,
+    matches.hidden
+FROM (
+    SELECT
+        rule_matches.user_id,
+        rule_matches.post_id,
+        bool_or(rule_matches.action = 'read') AS read,
+        bool_or(rule_matches.action = 'star') AS starred,
+        bool_or(rule_matches.action = 'hide') AS hidden
+    FROM rule_matches
+    WHERE (sqlc.narg(user_id)::uuid IS NULL OR rule_matches.user_id = sqlc.narg(user_id)::uuid)
+    AND (sqlc.narg(rule_id)::uuid IS NULL OR rule_matches.rule_id = sqlc.narg(rule_id)::uuid)
+    AND (sqlc.narg(post_id)::uuid IS NULL OR rule_matches.post_id = sqlc.narg(post_id)::uuid)
+    GROUP BY rule_matches.user_id, rule_matches.post_id
+) matches
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET
+    updated_at = NOW(),
+    read = post_states.read OR EXCLUDED.read,
+    read_at = COALESCE(post_states.read_at, EXCLUDED.read_at),
+    starred = post_states.starred OR EXCLUDED.starred,
+    starred_at = COALESCE(post_states.starred_at, EXCLUDED.starred_at),
+    hidden = post_states.hidden OR EXCLUDED.hidden;
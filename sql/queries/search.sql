@@ -0,0 +1,26 @@
+-- name: TextSearchConfigExists :one
+SELECT EXISTS (
+    SELECT 1
+    FROM pg_catalog.pg_ts_config
+    WHERE cfgname = $1
+);
+
+-- name: ReindexFeedSearch :execrows
+INSERT INTO post_search (post_id, language, document, text)
+SELECT
+    posts.id,
+    COALESCE(feeds.language, 'simple')::regconfig,
+    post_search_document(COALESCE(feeds.language, 'simple')::regconfig, posts.title, posts.description, posts.content),
+    post_search_text(posts.description, posts.content)
+FROM posts
+INNER JOIN feeds ON feeds.id = posts.feed_id
+WHERE feeds.id = $1
+ON CONFLICT (post_id) DO UPDATE
+SET (language, document, text) = (EXCLUDED.language, EXCLUDED.document, EXCLUDED.text);
+
+-- name: ListSearchLanguages :many
+SELECT DISTINCT COALESCE(feeds.language, 'simple')::text AS language
+FROM feeds
+INNER JOIN feed_follows ON feed_follows.feed_id = feeds.id
+WHERE feed_follows.user_id = $1
+ORDER BY language;
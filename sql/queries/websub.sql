@@ -0,0 +1,51 @@
+-- name: UpsertWebsubSubscription :one
+INSERT INTO websub_subscriptions (id, created_at, updated_at, feed_id, hub_url, topic_url, secret)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7
+)
+ON CONFLICT (feed_id) DO UPDATE
+SET
+    (updated_at, hub_url, topic_url, lease_expires_at) = (
+        EXCLUDED.updated_at,
+        EXCLUDED.hub_url,
+        EXCLUDED.topic_url,
+        CASE
+            WHEN websub_subscriptions.hub_url = EXCLUDED.hub_url AND websub_subscriptions.topic_url = EXCLUDED.topic_url
+            THEN websub_subscriptions.lease_expires_at
+        END
+    )
+RETURNING *;
+
+-- name: GetWebsubSubscription :one
+SELECT * FROM websub_subscriptions
+WHERE id = $1;
+
+-- name: SetWebsubLease :exec
+UPDATE websub_subscriptions
+SET
+    (updated_at, lease_expires_at, pending_mode) = (NOW(), $2, NULL)
+WHERE id = $1;
+
+-- name: SetWebsubPending :exec
+UPDATE websub_subscriptions
+SET
+    (updated_at, pending_mode) = (NOW(), $2)
+WHERE id = $1;
+
+-- name: GetOrphanedWebsubSubscriptions :many
+SELECT * FROM websub_subscriptions
+WHERE feed_id IN (
+    SELECT id
+    FROM feeds
+    WHERE hub_url IS NULL
+);
+
+-- name: DeleteWebsubSubscription :exec
+DELETE FROM websub_subscriptions
+WHERE id = $1;
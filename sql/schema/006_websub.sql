@@ -0,0 +1,24 @@
+-- +goose Up
+ALTER TABLE feeds
+ADD COLUMN hub_url TEXT NULL,
+ADD COLUMN hub_topic TEXT NULL;
+
+CREATE TABLE websub_subscriptions (
+	id UUID PRIMARY KEY,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL,
+	feed_id UUID NOT NULL,
+	FOREIGN KEY (feed_id) REFERENCES feeds(id) ON DELETE CASCADE,
+	UNIQUE(feed_id),
+	hub_url TEXT NOT NULL,
+	topic_url TEXT NOT NULL,
+	secret TEXT NOT NULL,
+	lease_expires_at TIMESTAMP NULL
+);
+
+-- +goose Down
+DROP TABLE websub_subscriptions;
+
+ALTER TABLE feeds
+DROP COLUMN hub_url,
+DROP COLUMN hub_topic;
@@ -0,0 +1,19 @@
+-- +goose Up
+CREATE TABLE fetch_log (
+	id UUID PRIMARY KEY,
+	feed_id UUID NOT NULL,
+	FOREIGN KEY (feed_id) REFERENCES feeds(id) ON DELETE CASCADE,
+	started_at TIMESTAMP NOT NULL,
+	finished_at TIMESTAMP NOT NULL,
+	http_status INTEGER NULL,
+	bytes BIGINT NOT NULL,
+	items_seen INTEGER NOT NULL,
+	new_posts INTEGER NOT NULL,
+	error TEXT NULL
+);
+
+CREATE INDEX fetch_log_feed_id_started_at_idx ON fetch_log (feed_id, started_at DESC);
+CREATE INDEX fetch_log_started_at_idx ON fetch_log (started_at);
+
+-- +goose Down
+DROP TABLE fetch_log;
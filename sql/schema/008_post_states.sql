@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE post_states (
+	user_id UUID NOT NULL,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+	post_id UUID NOT NULL,
+	FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+	PRIMARY KEY (user_id, post_id),
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL,
+	read BOOLEAN NOT NULL DEFAULT false,
+	read_at TIMESTAMP NULL
+);
+
+-- +goose Down
+DROP TABLE post_states;
@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE post_states
+ADD COLUMN starred BOOLEAN NOT NULL DEFAULT false,
+ADD COLUMN starred_at TIMESTAMP NULL;
+
+-- +goose Down
+ALTER TABLE post_states
+DROP COLUMN starred,
+DROP COLUMN starred_at;
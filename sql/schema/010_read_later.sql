@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE read_later (
+	user_id UUID NOT NULL,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+	post_id UUID NOT NULL,
+	FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+	PRIMARY KEY (user_id, post_id),
+	created_at TIMESTAMP NOT NULL,
+	position INTEGER NOT NULL
+);
+
+-- +goose Down
+DROP TABLE read_later;
@@ -0,0 +1,23 @@
+-- +goose Up
+CREATE TABLE folders (
+	id UUID PRIMARY KEY,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL,
+	user_id UUID NOT NULL,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+	name TEXT NOT NULL,
+	UNIQUE (user_id, name)
+);
+
+CREATE TABLE folder_feeds (
+	folder_id UUID NOT NULL,
+	FOREIGN KEY (folder_id) REFERENCES folders(id) ON DELETE CASCADE,
+	feed_follow_id UUID NOT NULL,
+	FOREIGN KEY (feed_follow_id) REFERENCES feed_follows(id) ON DELETE CASCADE,
+	PRIMARY KEY (folder_id, feed_follow_id),
+	created_at TIMESTAMP NOT NULL
+);
+
+-- +goose Down
+DROP TABLE folder_feeds;
+DROP TABLE folders;
@@ -0,0 +1,13 @@
+-- +goose Up
+ALTER TABLE posts
+ADD COLUMN author TEXT NOT NULL DEFAULT '',
+ADD COLUMN categories TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX posts_published_at_id_idx ON posts (published_at DESC, id DESC);
+
+-- +goose Down
+DROP INDEX posts_published_at_id_idx;
+
+ALTER TABLE posts
+DROP COLUMN author,
+DROP COLUMN categories;
@@ -0,0 +1,62 @@
+-- +goose Up
+ALTER TABLE feeds
+ADD COLUMN language TEXT;
+
+ALTER TABLE posts
+ADD COLUMN content TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE post_search (
+	post_id UUID PRIMARY KEY,
+	language REGCONFIG NOT NULL,
+	document TSVECTOR NOT NULL,
+	FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE
+);
+
+CREATE INDEX post_search_document_idx ON post_search USING GIN (document);
+
+-- +goose StatementBegin
+CREATE FUNCTION post_search_document(language REGCONFIG, title TEXT, description TEXT, content TEXT)
+RETURNS TSVECTOR AS $$
+    SELECT setweight(to_tsvector(language, title), 'A')
+        || setweight(to_tsvector(language, description), 'B')
+        || setweight(to_tsvector(language, content), 'C');
+$$ LANGUAGE SQL IMMUTABLE;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE FUNCTION index_post_search() RETURNS TRIGGER AS $$
+DECLARE
+    config REGCONFIG;
+BEGIN
+    SELECT COALESCE(feeds.language, 'simple')::regconfig INTO config
+    FROM feeds
+    WHERE feeds.id = NEW.feed_id;
+
+    INSERT INTO post_search (post_id, language, document)
+    VALUES (NEW.id, config, post_search_document(config, NEW.title, NEW.description, NEW.content))
+    ON CONFLICT (post_id) DO UPDATE
+    SET (language, document) = (EXCLUDED.language, EXCLUDED.document);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER posts_search_idx
+AFTER INSERT OR UPDATE OF title, description, content ON posts
+FOR EACH ROW EXECUTE FUNCTION index_post_search();
+
+INSERT INTO post_search (post_id, language, document)
+SELECT posts.id, 'simple', post_search_document('simple', posts.title, posts.description, posts.content)
+FROM posts;
+
+-- +goose Down
+DROP TRIGGER posts_search_idx ON posts;
+DROP FUNCTION index_post_search();
+DROP FUNCTION post_search_document(REGCONFIG, TEXT, TEXT, TEXT);
+DROP TABLE post_search;
+
+ALTER TABLE posts
+DROP COLUMN content;
+
+ALTER TABLE feeds
+DROP COLUMN language;
@@ -0,0 +1,40 @@
+-- +goose Up
+ALTER TABLE post_states
+ADD COLUMN hidden BOOLEAN NOT NULL DEFAULT false;
+
+CREATE TABLE rules (
+	id UUID PRIMARY KEY,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL,
+	user_id UUID NOT NULL,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+	action TEXT NOT NULL CHECK (action IN ('read', 'star', 'hide')),
+	feed_id UUID NULL,
+	FOREIGN KEY (feed_id) REFERENCES feeds(id) ON DELETE CASCADE,
+	title_pattern TEXT NULL,
+	description_pattern TEXT NULL,
+	author TEXT NULL,
+	category TEXT NULL
+);
+
+CREATE VIEW rule_matches AS
+SELECT rules.id AS rule_id, rules.user_id, rules.action, posts.id AS post_id
+FROM rules
+INNER JOIN feed_follows ON feed_follows.user_id = rules.user_id
+INNER JOIN posts ON posts.feed_id = feed_follows.feed_id
+WHERE (rules.feed_id IS NULL OR rules.feed_id = posts.feed_id)
+AND (rules.title_pattern IS NULL OR posts.title ~* rules.title_pattern)
+AND (rules.description_pattern IS NULL OR posts.description ~* rules.description_pattern)
+AND (rules.author IS NULL OR posts.author ILIKE '%' || rules.author || '%')
+AND (rules.category IS NULL OR EXISTS (
+    SELECT 1
+    FROM unnest(posts.categories) category
+    WHERE lower(category) = lower(rules.category)
+));
+
+-- +goose Down
+DROP VIEW rule_matches;
+DROP TABLE rules;
+
+ALTER TABLE post_states
+DROP COLUMN hidden;
@@ -0,0 +1,25 @@
+-- +goose Up
+ALTER TABLE posts
+ADD COLUMN guid TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE feeds
+ADD COLUMN retention_days INT NULL,
+ADD COLUMN retention_posts INT NULL;
+
+CREATE TABLE post_tombstones (
+	feed_id UUID NOT NULL,
+	FOREIGN KEY (feed_id) REFERENCES feeds(id) ON DELETE CASCADE,
+	guid TEXT NOT NULL,
+	PRIMARY KEY (feed_id, guid),
+	pruned_at TIMESTAMP NOT NULL
+);
+
+-- +goose Down
+DROP TABLE post_tombstones;
+
+ALTER TABLE feeds
+DROP COLUMN retention_days,
+DROP COLUMN retention_posts;
+
+ALTER TABLE posts
+DROP COLUMN guid;
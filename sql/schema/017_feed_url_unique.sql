@@ -0,0 +1,76 @@
+-- +goose Up
+-- feeds added more than once under the same URL are merged into the oldest
+CREATE TEMPORARY TABLE feed_merges AS
+SELECT id AS duplicate_id, first_value(id) OVER (PARTITION BY url ORDER BY created_at, id) AS feed_id
+FROM feeds;
+
+DELETE FROM feed_merges
+WHERE duplicate_id = feed_id;
+
+-- a user following more than one copy keeps a single follow, the one of the
+-- kept feed if there is one
+CREATE TEMPORARY TABLE follow_merges AS
+SELECT duplicate_id, follow_id
+FROM (
+    SELECT feed_follows.id AS duplicate_id, first_value(feed_follows.id) OVER (
+        PARTITION BY feed_follows.user_id, COALESCE(feed_merges.feed_id, feed_follows.feed_id)
+        ORDER BY feed_merges.feed_id IS NOT NULL, feed_follows.created_at, feed_follows.id
+    ) AS follow_id
+    FROM feed_follows
+    LEFT JOIN feed_merges ON feed_follows.feed_id = feed_merges.duplicate_id
+) follows
+WHERE duplicate_id <> follow_id;
+
+INSERT INTO folder_feeds (folder_id, feed_follow_id, created_at)
+SELECT folder_feeds.folder_id, follow_merges.follow_id, folder_feeds.created_at
+FROM folder_feeds
+INNER JOIN follow_merges ON folder_feeds.feed_follow_id = follow_merges.duplicate_id
+ON CONFLICT DO NOTHING;
+
+DELETE FROM feed_follows
+USING follow_merges
+WHERE feed_follows.id = follow_merges.duplicate_id;
+
+UPDATE feed_follows
+SET feed_id = feed_merges.feed_id
+FROM feed_merges
+WHERE feed_follows.feed_id = feed_merges.duplicate_id;
+
+UPDATE posts
+SET feed_id = feed_merges.feed_id
+FROM feed_merges
+WHERE posts.feed_id = feed_merges.duplicate_id;
+
+-- keep pruned posts from coming back under the kept feed
+INSERT INTO post_tombstones (feed_id, guid, pruned_at)
+SELECT feed_merges.feed_id, post_tombstones.guid, post_tombstones.pruned_at
+FROM post_tombstones
+INNER JOIN feed_merges ON post_tombstones.feed_id = feed_merges.duplicate_id
+ON CONFLICT DO NOTHING;
+
+UPDATE fetch_log
+SET feed_id = feed_merges.feed_id
+FROM feed_merges
+WHERE fetch_log.feed_id = feed_merges.duplicate_id;
+
+UPDATE rules
+SET feed_id = feed_merges.feed_id
+FROM feed_merges
+WHERE rules.feed_id = feed_merges.duplicate_id;
+
+DELETE FROM feeds
+USING feed_merges
+WHERE feeds.id = feed_merges.duplicate_id;
+
+DROP TABLE follow_merges;
+DROP TABLE feed_merges;
+
+ALTER TABLE feeds
+DROP CONSTRAINT feeds_user_id_key,
+ADD CONSTRAINT feeds_url_key UNIQUE (url);
+
+-- +goose Down
+-- only partly reversible: merged feeds stay merged, and feeds stay free of
+-- the one feed per user limit, which users who added several would break
+ALTER TABLE feeds
+DROP CONSTRAINT feeds_url_key;
@@ -0,0 +1,72 @@
+-- +goose Up
+ALTER TABLE post_search
+ADD COLUMN text TEXT NOT NULL DEFAULT '';
+
+-- html_text drops the comments and tags of an HTML fragment, leaving entities
+-- for the reader to decode
+-- +goose StatementBegin
+CREATE FUNCTION html_text(html TEXT)
+RETURNS TEXT AS $$
+    SELECT regexp_replace(regexp_replace(html, '<!--.*?-->', ' ', 'g'), '<[^>]*>', ' ', 'g');
+$$ LANGUAGE SQL IMMUTABLE;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE FUNCTION post_search_text(description TEXT, content TEXT)
+RETURNS TEXT AS $$
+    SELECT html_text(description) || ' ' || html_text(content);
+$$ LANGUAGE SQL IMMUTABLE;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION index_post_search() RETURNS TRIGGER AS $$
+DECLARE
+    config REGCONFIG;
+BEGIN
+    SELECT COALESCE(feeds.language, 'simple')::regconfig INTO config
+    FROM feeds
+    WHERE feeds.id = NEW.feed_id;
+
+    INSERT INTO post_search (post_id, language, document, text)
+    VALUES (
+        NEW.id,
+        config,
+        post_search_document(config, NEW.title, NEW.description, NEW.content),
+        post_search_text(NEW.description, NEW.content)
+    )
+    ON CONFLICT (post_id) DO UPDATE
+    SET (language, document, text) = (EXCLUDED.language, EXCLUDED.document, EXCLUDED.text);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+UPDATE post_search
+SET text = post_search_text(posts.description, posts.content)
+FROM posts
+WHERE posts.id = post_search.post_id;
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION index_post_search() RETURNS TRIGGER AS $$
+DECLARE
+    config REGCONFIG;
+BEGIN
+    SELECT COALESCE(feeds.language, 'simple')::regconfig INTO config
+    FROM feeds
+    WHERE feeds.id = NEW.feed_id;
+
+    INSERT INTO post_search (post_id, language, document)
+    VALUES (NEW.id, config, post_search_document(config, NEW.title, NEW.description, NEW.content))
+    ON CONFLICT (post_id) DO UPDATE
+    SET (language, document) = (EXCLUDED.language, EXCLUDED.document);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+DROP FUNCTION post_search_text(TEXT, TEXT);
+DROP FUNCTION html_text(TEXT);
+
+ALTER TABLE post_search
+DROP COLUMN text;
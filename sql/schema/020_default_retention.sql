@@ -0,0 +1,17 @@
+-- +goose Up
+-- the retention of feeds without their own, a single row
+CREATE TABLE default_retention (
+	id BOOLEAN PRIMARY KEY DEFAULT true CHECK (id),
+	updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+	days INT NOT NULL DEFAULT 0,
+	posts INT NOT NULL DEFAULT 0
+);
+
+INSERT INTO default_retention DEFAULT VALUES;
+
+CREATE INDEX post_tombstones_pruned_at_idx ON post_tombstones (pruned_at);
+
+-- +goose Down
+DROP INDEX post_tombstones_pruned_at_idx;
+
+DROP TABLE default_retention;
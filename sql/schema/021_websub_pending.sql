@@ -0,0 +1,8 @@
+-- +goose Up
+-- the mode of the request sent to the hub and not verified yet
+ALTER TABLE websub_subscriptions
+ADD COLUMN pending_mode TEXT NULL;
+
+-- +goose Down
+ALTER TABLE websub_subscriptions
+DROP COLUMN pending_mode;
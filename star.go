@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jdwalkerzhere/gator/internal/database"
+)
+
+func handlerStar(s *state, cmd command) error {
+	return setPostsStarred(s, cmd, true)
+}
+
+func handlerUnstar(s *state, cmd command) error {
+	return setPostsStarred(s, cmd, false)
+}
+
+func setPostsStarred(s *state, cmd command, starred bool) error {
+	if err := cmd.parseNoFlags(); err != nil {
+		return err
+	}
+	if len(cmd.args) == 0 {
+		return fmt.Errorf("No posts provided, please provide post IDs or URLs\n")
+	}
+
+	user, err := s.db.GetUser(context.Background(), s.cfg.CurrentUser)
+	if err != nil {
+		return err
+	}
+
+	for _, ref := range cmd.args {
+		post, err := findPost(context.Background(), s, ref)
+		if err != nil {
+			return err
+		}
+		starParams := database.SetPostStarredParams{
+			UserID:    user.ID,
+			PostID:    post.ID,
+			Starred:   starred,
+			StarredAt: sql.NullTime{Time: time.Now(), Valid: starred},
+		}
+		err = s.db.SetPostStarred(context.Background(), starParams)
+		if err != nil {
+			return err
+		}
+		if starred {
+			fmt.Printf("Starred [%s]\n", post.Title)
+		} else {
+			fmt.Printf("Unstarred [%s]\n", post.Title)
+		}
+	}
+	return nil
+}
+
+func handlerSaved(s *state, cmd command) error {
+	if err := cmd.parseNoFlags(); err != nil {
+		return err
+	}
+	var limit int32 = 10
+	if len(cmd.args) > 0 {
+		parsedLimit, err := strconv.ParseInt(cmd.args[0], 10, 32)
+		if err != nil {
+			return err
+		}
+		limit = int32(parsedLimit)
+	}
+
+	user, err := s.db.GetUser(context.Background(), s.cfg.CurrentUser)
+	if err != nil {
+		return err
+	}
+
+	starredParams := database.GetStarredPostsForUserParams{
+		UserID: user.ID,
+		Limit:  limit,
+	}
+	posts, err := s.db.GetStarredPostsForUser(context.Background(), starredParams)
+	if err != nil {
+		return err
+	}
+	records := make([]starredRecord, len(posts))
+	for i, post := range posts {
+		records[i] = starredRecord{
+			ID:          post.ID,
+			Title:       post.Title,
+			URL:         post.Url,
+			PublishedAt: post.PublishedAt,
+			StarredAt:   post.StarredAt.Time,
+		}
+	}
+	return printRecords(s, records, func() {
+		for i, post := range records {
+			fmt.Printf("%d. %s\n\tID: %s\n\tStarred: %s\n\tLink: %s\n\n", i+1, post.Title, post.ID, post.StarredAt.Format(time.DateTime), post.URL)
+		}
+	})
+}
+
+type starredRecord struct {
+	ID          uuid.UUID `json:"id"`
+	Title       string    `json:"title"`
+	URL         string    `json:"url"`
+	PublishedAt time.Time `json:"published_at"`
+	StarredAt   time.Time `json:"starred_at"`
+}
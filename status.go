@@ -0,0 +1,296 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jdwalkerzhere/gator/internal/database"
+)
+
+type aggStatus struct {
+	mu        sync.Mutex
+	startedAt time.Time
+	interval  time.Duration
+	leader    bool
+	nextFetch time.Time
+	fetches   int
+	failures  int
+	newPosts  int
+	inFlight  map[uuid.UUID]statusFetch
+	feedFails map[uuid.UUID]feedFailures
+}
+
+type feedFailures struct {
+	count     int
+	lastError string
+}
+
+type statusReport struct {
+	PID       int           `json:"pid"`
+	StartedAt time.Time     `json:"started_at"`
+	Interval  string        `json:"interval"`
+	Leader    bool          `json:"leader"`
+	Fetches   int           `json:"fetches"`
+	Failures  int           `json:"failures"`
+	NewPosts  int           `json:"new_posts"`
+	InFlight  []statusFetch `json:"in_flight"`
+	Queue     []statusFeed  `json:"queue"`
+}
+
+type statusFetch struct {
+	Feed      string    `json:"feed"`
+	URL       string    `json:"url"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+type statusFeed struct {
+	Feed          string     `json:"feed"`
+	URL           string     `json:"url"`
+	LastFetchedAt *time.Time `json:"last_fetched_at"`
+	NextFetchAt   *time.Time `json:"next_fetch_at"`
+	Failures      int        `json:"failures"`
+	LastError     string     `json:"last_error,omitempty"`
+}
+
+func newAggStatus(interval time.Duration) *aggStatus {
+	return &aggStatus{
+		startedAt: time.Now(),
+		interval:  interval,
+		inFlight:  make(map[uuid.UUID]statusFetch),
+		feedFails: make(map[uuid.UUID]feedFailures),
+	}
+}
+
+func (a *aggStatus) setLeader(leader bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.leader = leader
+}
+
+func (a *aggStatus) scheduled(next time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.nextFetch = next
+}
+
+func (a *aggStatus) fetchStarted(feed database.Feed) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.inFlight[feed.ID] = statusFetch{Feed: feed.Name, URL: feed.Url, StartedAt: time.Now()}
+}
+
+func (a *aggStatus) fetchFinished(result scrapeResult, err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.inFlight, result.feed.ID)
+	a.fetches++
+	a.newPosts += result.newPosts
+	if err == nil {
+		return
+	}
+	a.failures++
+	if result.feed.ID != uuid.Nil {
+		fails := a.feedFails[result.feed.ID]
+		fails.count++
+		fails.lastError = err.Error()
+		a.feedFails[result.feed.ID] = fails
+	}
+}
+
+func (a *aggStatus) summaryLine() string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return fmt.Sprintf("Aggregator stopped after %s: %d fetches, %d failed, %d new posts",
+		time.Since(a.startedAt).Round(time.Second), a.fetches, a.failures, a.newPosts)
+}
+
+func (a *aggStatus) report(queue []database.Feed) statusReport {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	report := statusReport{
+		PID:       os.Getpid(),
+		StartedAt: a.startedAt,
+		Interval:  a.interval.String(),
+		Leader:    a.leader,
+		Fetches:   a.fetches,
+		Failures:  a.failures,
+		NewPosts:  a.newPosts,
+		InFlight:  []statusFetch{},
+		Queue:     []statusFeed{},
+	}
+	for _, fetch := range a.inFlight {
+		report.InFlight = append(report.InFlight, fetch)
+	}
+
+	// one feed is fetched per tick, going round the queue oldest first, so a
+	// feed is due a full round after it was last fetched, though never before
+	// the ticks for the feeds ahead of it
+	round := time.Duration(len(queue)) * a.interval
+	for i, feed := range queue {
+		fails := a.feedFails[feed.ID]
+		entry := statusFeed{
+			Feed:      feed.Name,
+			URL:       feed.Url,
+			Failures:  fails.count,
+			LastError: fails.lastError,
+		}
+		if feed.LastFetchedAt.Valid {
+			entry.LastFetchedAt = &feed.LastFetchedAt.Time
+		}
+		if a.leader && !a.nextFetch.IsZero() {
+			next := a.nextFetch.Add(time.Duration(i) * a.interval)
+			if feed.LastFetchedAt.Valid {
+				if due := feed.LastFetchedAt.Time.Add(round); due.After(next) {
+					next = due
+				}
+			}
+			entry.NextFetchAt = &next
+		}
+		report.Queue = append(report.Queue, entry)
+	}
+	return report
+}
+
+func serveAggStatus(s *state, status *aggStatus) (func(), error) {
+	socketPath, err := s.cfg.StatusSocketPath()
+	if err != nil {
+		return nil, err
+	}
+	listener, err := listenStatusSocket(socketPath)
+	if err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /status", func(w http.ResponseWriter, r *http.Request) {
+		queue, err := s.db.GetFeedFetchQueue(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(status.report(queue))
+	})
+
+	server := &http.Server{Handler: mux}
+	go server.Serve(listener)
+	return func() {
+		server.Close()
+	}, nil
+}
+
+func listenStatusSocket(path string) (net.Listener, error) {
+	err := os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return nil, err
+	}
+	listener, err := net.Listen("unix", path)
+	if err == nil {
+		return listener, restrictStatusSocket(listener, path)
+	}
+
+	conn, dialErr := net.Dial("unix", path)
+	if dialErr == nil {
+		conn.Close()
+		return nil, fmt.Errorf("Another aggregator is already reporting on [%s]", path)
+	}
+	// nobody answers, so the socket was left behind by an agg that died
+	os.Remove(path)
+	listener, err = net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	return listener, restrictStatusSocket(listener, path)
+}
+
+// The status report names every feed, so only its owner may connect.
+func restrictStatusSocket(listener net.Listener, path string) error {
+	err := os.Chmod(path, 0600)
+	if err != nil {
+		listener.Close()
+	}
+	return err
+}
+
+func fetchAggStatus(socketPath string) (statusReport, error) {
+	client := http.Client{
+		Timeout: 5 * time.Second,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", socketPath)
+			},
+		},
+	}
+	res, err := client.Get("http://gator/status")
+	if err != nil {
+		return statusReport{}, fmt.Errorf("No aggregator answering on [%s], is `gator agg` running?", socketPath)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return statusReport{}, fmt.Errorf("Aggregator status request failed: %s", res.Status)
+	}
+
+	report := statusReport{}
+	err = json.NewDecoder(res.Body).Decode(&report)
+	return report, err
+}
+
+func handlerStatus(s *state, cmd command) error {
+	if err := cmd.parseNoFlags(); err != nil {
+		return err
+	}
+	// the report holds lists of fetches and feeds, which don't fit in rows
+	if s.output.tabular() {
+		return fmt.Errorf("Status can't be printed as %s, please use --output json or jsonl\n", s.output)
+	}
+	socketPath, err := s.cfg.StatusSocketPath()
+	if err != nil {
+		return err
+	}
+	report, err := fetchAggStatus(socketPath)
+	if err != nil {
+		return err
+	}
+
+	return printRecords(s, []statusReport{report}, func() {
+		role := "standby"
+		if report.Leader {
+			role = "leader"
+		}
+		fmt.Printf("Aggregator [pid %d] running since %s as %s, fetching every %s\n",
+			report.PID, report.StartedAt.Format(time.RFC1123), role, report.Interval)
+		fmt.Printf("Fetches: %d (%d failed), %d new posts\n", report.Fetches, report.Failures, report.NewPosts)
+
+		fmt.Println("In flight:")
+		if len(report.InFlight) == 0 {
+			fmt.Println("\t(none)")
+		}
+		for _, fetch := range report.InFlight {
+			fmt.Printf("\t* %s (%s) since %s\n", fetch.Feed, fetch.URL, fetch.StartedAt.Format(time.TimeOnly))
+		}
+
+		fmt.Println("Queue:")
+		for i, feed := range report.Queue {
+			last, next := "never", "-"
+			if feed.LastFetchedAt != nil {
+				last = feed.LastFetchedAt.Format(time.DateTime)
+			}
+			if feed.NextFetchAt != nil {
+				next = feed.NextFetchAt.Format(time.DateTime)
+			}
+			fmt.Printf("%d. %s\n\t- URL: %s\n\t- Last Fetched: %s\n\t- Next Fetch: %s\n", i+1, feed.Feed, feed.URL, last, next)
+			if feed.Failures > 0 {
+				fmt.Printf("\t- Failures: %d, last error: %s\n", feed.Failures, feed.LastError)
+			}
+		}
+	})
+}
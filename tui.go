@@ -0,0 +1,682 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jdwalkerzhere/gator/internal/database"
+	"github.com/jdwalkerzhere/gator/internal/markup"
+	"github.com/jdwalkerzhere/gator/internal/postquery"
+	"github.com/jdwalkerzhere/gator/internal/term"
+	"github.com/lib/pq"
+)
+
+// newPostsChannel is the channel NotifyNewPosts notifies when agg saves new
+// posts.
+const newPostsChannel = "gator_posts"
+
+const tuiHints = "q quit  tab pane  enter open  n next unread  m read  s star  o browser  a show read  r refresh"
+
+type tuiPane int
+
+const (
+	paneSources tuiPane = iota
+	panePosts
+	paneReader
+)
+
+// tuiSource is a line of the feed and folder pane. Headings only label the
+// lines below them; the rest pick the posts to list.
+type tuiSource struct {
+	label   string
+	heading bool
+	feed    string
+	folder  string
+	starred bool
+	query   string
+}
+
+// tui is the state of the full-screen reader. Only the event loop touches it.
+type tui struct {
+	s        *state
+	user     database.User
+	term     *term.Terminal
+	limit    int32
+	showRead bool
+
+	width  int
+	height int
+	focus  tuiPane
+
+	sources   []tuiSource
+	source    int
+	sourceTop int
+
+	posts   []postquery.Post
+	post    int
+	postTop int
+
+	// reader holds the selected post laid out for the reader pane, for the
+	// post and width it was laid out for.
+	reader      []string
+	readerPost  uuid.UUID
+	readerWidth int
+	readerTop   int
+
+	message string
+}
+
+func handlerTUI(s *state, cmd command) error {
+	flags := newFlagSet(cmd)
+	showRead := flags.Bool("all", false, "start out listing posts already marked read too")
+	limit := flags.Int("limit", 200, "the most posts to list at a time")
+	err := flags.Parse(cmd.args)
+	if err != nil {
+		return err
+	}
+	if !isTerminal(os.Stdin) || !isTerminal(os.Stdout) {
+		return fmt.Errorf("The reader needs a terminal, use browse to list posts in scripts\n")
+	}
+
+	user, err := s.db.GetUser(context.Background(), s.cfg.CurrentUser)
+	if err != nil {
+		return err
+	}
+	t := &tui{s: s, user: user, limit: int32(*limit), showRead: *showRead, focus: panePosts}
+	err = t.loadSources(context.Background())
+	if err != nil {
+		return err
+	}
+	err = t.loadPosts(context.Background())
+	if err != nil {
+		return err
+	}
+
+	t.term, err = term.Open()
+	if err != nil {
+		return err
+	}
+	defer t.term.Close()
+	return t.run()
+}
+
+func (t *tui) run() error {
+	keys := t.term.Keys()
+	resized := t.term.Resized()
+
+	// agg notifies the channel for every fetch that saved posts, so the
+	// lists stay current while it runs
+	var notifications <-chan *pq.Notification
+	listener := pq.NewListener(t.s.cfg.DbURL, 10*time.Second, time.Minute, nil)
+	defer listener.Close()
+	err := listener.Listen(newPostsChannel)
+	if err != nil {
+		t.message = fmt.Sprintf("Live refresh is off: %v", err)
+	} else {
+		notifications = listener.Notify
+	}
+
+	for {
+		err := t.draw()
+		if err != nil {
+			return err
+		}
+		select {
+		case key, ok := <-keys:
+			if !ok {
+				return nil
+			}
+			if t.handleKey(key) {
+				return nil
+			}
+		case <-resized:
+		case <-notifications:
+			// a burst of fetches needs only one reload
+			for len(notifications) > 0 {
+				<-notifications
+			}
+			t.reload("New posts arrived")
+		}
+	}
+}
+
+// handleKey acts on a key press and reports whether to quit.
+func (t *tui) handleKey(key term.Key) bool {
+	t.message = ""
+	switch key {
+	case "q", term.KeyCtrlC:
+		return true
+	case term.KeyTab:
+		t.focus = (t.focus + 1) % 3
+	case term.KeyBackTab:
+		t.focus = (t.focus + 2) % 3
+	case "h", term.KeyLeft:
+		t.focus = max(t.focus-1, paneSources)
+	case "l", term.KeyRight:
+		t.focus = min(t.focus+1, paneReader)
+	case term.KeyEscape:
+		if t.focus == paneReader {
+			t.focus = panePosts
+		}
+	case "j", term.KeyDown:
+		t.move(1)
+	case "k", term.KeyUp:
+		t.move(-1)
+	case " ", term.KeyPageDown:
+		t.move(t.paneHeight())
+	case "b", term.KeyPageUp:
+		t.move(-t.paneHeight())
+	case "g", term.KeyHome:
+		t.move(-1 << 30)
+	case "G", term.KeyEnd:
+		t.move(1 << 30)
+	case term.KeyEnter:
+		switch t.focus {
+		case paneSources:
+			t.focus = panePosts
+		case panePosts:
+			t.openPost()
+		}
+	case "n":
+		t.nextUnread()
+	case "m":
+		if post, ok := t.selectedPost(); ok {
+			t.setRead(post, !post.Read)
+		}
+	case "s":
+		t.toggleStar()
+	case "o":
+		t.openInBrowser()
+	case "a":
+		t.showRead = !t.showRead
+		t.reload("")
+	case "r":
+		t.reload("Refreshed")
+	}
+	return false
+}
+
+// move moves the selection of the focused pane, or scrolls the reader.
+func (t *tui) move(delta int) {
+	switch t.focus {
+	case paneSources:
+		next := clampIndex(t.source+delta, len(t.sources))
+		// headings can't be picked, so step past them the way we're going
+		step := 1
+		if delta < 0 {
+			step = -1
+		}
+		for next > 0 && next < len(t.sources)-1 && t.sources[next].heading {
+			next += step
+		}
+		if next != t.source && !t.sources[next].heading {
+			t.source = next
+			t.posts, t.post = nil, 0
+			t.reload("")
+		}
+	case panePosts:
+		t.post = clampIndex(t.post+delta, len(t.posts))
+	case paneReader:
+		t.readerTop = clampIndex(t.readerTop+delta, max(len(t.reader)-t.readerHeight()+1, 1))
+	}
+}
+
+func clampIndex(i, length int) int {
+	return max(0, min(i, length-1))
+}
+
+func (t *tui) selectedPost() (postquery.Post, bool) {
+	if len(t.posts) == 0 {
+		return postquery.Post{}, false
+	}
+	return t.posts[t.post], true
+}
+
+// openPost shows the selected post in the reader and marks it read.
+func (t *tui) openPost() {
+	post, ok := t.selectedPost()
+	if !ok {
+		return
+	}
+	t.focus = paneReader
+	t.readerTop = 0
+	if !post.Read {
+		t.setRead(post, true)
+	}
+}
+
+func (t *tui) nextUnread() {
+	for i := 1; i <= len(t.posts); i++ {
+		next := (t.post + i) % len(t.posts)
+		if !t.posts[next].Read {
+			t.post = next
+			t.openPost()
+			return
+		}
+	}
+	t.message = "No unread posts left here"
+}
+
+func (t *tui) setRead(post postquery.Post, read bool) {
+	ctx := context.Background()
+	readParams := database.SetPostReadParams{
+		UserID: t.user.ID,
+		PostID: post.ID,
+		Read:   read,
+		ReadAt: sql.NullTime{Time: time.Now(), Valid: read},
+	}
+	err := t.s.db.SetPostRead(ctx, readParams)
+	if err == nil && read {
+		_, err = t.s.db.RemoveReadFromReadLater(ctx, t.user.ID)
+	}
+	if err != nil {
+		t.message = fmt.Sprintf("Error marking [%s]: %v", post.Title, err)
+		return
+	}
+	// the post stays listed until the next reload, so it doesn't vanish
+	// from under the cursor
+	t.posts[t.post].Read = read
+}
+
+func (t *tui) toggleStar() {
+	post, ok := t.selectedPost()
+	if !ok {
+		return
+	}
+	starParams := database.SetPostStarredParams{
+		UserID:    t.user.ID,
+		PostID:    post.ID,
+		Starred:   !post.Starred,
+		StarredAt: sql.NullTime{Time: time.Now(), Valid: !post.Starred},
+	}
+	err := t.s.db.SetPostStarred(context.Background(), starParams)
+	if err != nil {
+		t.message = fmt.Sprintf("Error starring [%s]: %v", post.Title, err)
+		return
+	}
+	t.posts[t.post].Starred = !post.Starred
+}
+
+func (t *tui) openInBrowser() {
+	post, ok := t.selectedPost()
+	if !ok || post.Url == "" {
+		return
+	}
+	err := openBrowser(post.Url)
+	if err != nil {
+		t.message = fmt.Sprintf("Error opening [%s]: %v", post.Url, err)
+		return
+	}
+	t.message = "Opened " + post.Url
+	if !post.Read {
+		t.setRead(post, true)
+	}
+}
+
+// openBrowser opens link in $BROWSER, or the system's default browser.
+func openBrowser(link string) error {
+	var cmd *exec.Cmd
+	switch browser := os.Getenv("BROWSER"); {
+	case browser != "":
+		cmd = exec.Command(browser, link)
+	case runtime.GOOS == "darwin":
+		cmd = exec.Command("open", link)
+	case runtime.GOOS == "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", link)
+	default:
+		cmd = exec.Command("xdg-open", link)
+	}
+	err := cmd.Start()
+	if err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
+
+// reload lists the posts again, keeping the selected post if it's still
+// there.
+func (t *tui) reload(message string) {
+	err := t.loadPosts(context.Background())
+	if err != nil {
+		t.message = fmt.Sprintf("Error listing posts: %v", err)
+		return
+	}
+	t.message = message
+}
+
+func (t *tui) loadSources(ctx context.Context) error {
+	t.sources = []tuiSource{
+		{label: "All posts"},
+		{label: "Starred", starred: true},
+	}
+	folders, err := t.s.db.GetFoldersForUser(ctx, t.user.ID)
+	if err != nil {
+		return err
+	}
+	if len(folders) > 0 {
+		t.sources = append(t.sources, tuiSource{label: "Folders", heading: true})
+	}
+	for _, folder := range folders {
+		t.sources = append(t.sources, tuiSource{label: folder.Name, folder: folder.Name})
+	}
+	searches, err := t.s.db.GetSavedSearchesForUser(ctx, t.user.ID)
+	if err != nil {
+		return err
+	}
+	if len(searches) > 0 {
+		t.sources = append(t.sources, tuiSource{label: "Searches", heading: true})
+	}
+	for _, search := range searches {
+		t.sources = append(t.sources, tuiSource{label: search.Name, query: search.Query})
+	}
+
+	following, err := t.s.db.GetFollowingByFolder(ctx, t.user.ID)
+	if err != nil {
+		return err
+	}
+	// a feed in several folders is listed once for each
+	seen := make(map[string]bool)
+	var feeds []tuiSource
+	for _, feed := range following {
+		if !seen[feed.Url] {
+			seen[feed.Url] = true
+			feeds = append(feeds, tuiSource{label: feed.FeedName, feed: feed.Url})
+		}
+	}
+	sort.Slice(feeds, func(i, j int) bool {
+		return strings.ToLower(feeds[i].label) < strings.ToLower(feeds[j].label)
+	})
+	if len(feeds) > 0 {
+		t.sources = append(t.sources, tuiSource{label: "Feeds", heading: true})
+	}
+	t.sources = append(t.sources, feeds...)
+	return nil
+}
+
+func (t *tui) loadPosts(ctx context.Context) error {
+	source := t.sources[t.source]
+	filter := postquery.Filter{
+		UserID:     t.user.ID,
+		Feed:       source.feed,
+		Folder:     source.folder,
+		Starred:    source.starred,
+		UnreadOnly: !t.showRead,
+		Limit:      t.limit,
+	}
+	if source.query != "" {
+		query, err := postquery.Parse(source.query)
+		if err != nil {
+			return err
+		}
+		filter.Query = query
+	}
+	posts, err := postquery.List(ctx, t.s.sqlDB, filter)
+	if err != nil {
+		return err
+	}
+
+	selected, _ := t.selectedPost()
+	t.posts = posts
+	t.post = clampIndex(t.post, len(posts))
+	for i, post := range posts {
+		if post.ID == selected.ID {
+			t.post = i
+		}
+	}
+	return nil
+}
+
+// layout splits the screen into the sources pane on the left, and the post
+// list above the reader on the right, leaving the last line for the status.
+func (t *tui) layout() (sourcesWidth, listHeight int) {
+	sourcesWidth = max(12, min(32, t.width/4))
+	listHeight = max(3, (t.height-1)/3)
+	return sourcesWidth, listHeight
+}
+
+// paneHeight is the height of the focused pane, for paging.
+func (t *tui) paneHeight() int {
+	_, listHeight := t.layout()
+	switch t.focus {
+	case panePosts:
+		return listHeight
+	case paneReader:
+		return t.readerHeight()
+	}
+	return t.height - 1
+}
+
+func (t *tui) readerHeight() int {
+	_, listHeight := t.layout()
+	return max(1, t.height-1-listHeight-1)
+}
+
+func (t *tui) draw() error {
+	width, height, err := t.term.Size()
+	if err != nil {
+		return err
+	}
+	t.width, t.height = width, height
+	sourcesWidth, listHeight := t.layout()
+	rightWidth := max(1, width-sourcesWidth-1)
+	bodyHeight := max(1, height-1)
+
+	sources := t.drawSources(sourcesWidth, bodyHeight)
+	reader := t.drawReader(rightWidth, t.readerHeight())
+	right := append(t.drawPosts(rightWidth, listHeight), t.divider(rightWidth))
+	right = append(right, reader...)
+
+	lines := make([]string, 0, height)
+	for i := 0; i < bodyHeight; i++ {
+		line := sources[i] + term.Dim + "│" + term.Reset
+		if i < len(right) {
+			line += right[i]
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines, t.drawStatus(width))
+	return t.term.Draw(lines)
+}
+
+// drawLine styles a list line as selected, more strongly in the focused pane.
+func drawLine(text string, width int, selected, focused bool) string {
+	line := term.Fit(text, width)
+	switch {
+	case selected && focused:
+		return term.Reverse + line + term.Reset
+	case selected:
+		return term.Bold + line + term.Reset
+	}
+	return line
+}
+
+func (t *tui) drawSources(width, height int) []string {
+	t.sourceTop = scrollTo(t.sourceTop, t.source, height)
+	lines := make([]string, height)
+	for i := range lines {
+		row := t.sourceTop + i
+		if row >= len(t.sources) {
+			lines[i] = strings.Repeat(" ", width)
+			continue
+		}
+		source := t.sources[row]
+		if source.heading {
+			lines[i] = term.Dim + term.Fit(source.label, width) + term.Reset
+			continue
+		}
+		lines[i] = drawLine(" "+source.label, width, row == t.source, t.focus == paneSources)
+	}
+	return lines
+}
+
+func (t *tui) drawPosts(width, height int) []string {
+	t.postTop = scrollTo(t.postTop, t.post, height)
+	lines := make([]string, height)
+	for i := range lines {
+		lines[i] = strings.Repeat(" ", width)
+	}
+	if len(t.posts) == 0 {
+		empty := " No posts here"
+		if !t.showRead {
+			empty = " No unread posts here, press a to show read ones"
+		}
+		lines[0] = term.Fit(empty, width)
+	}
+	// the feed is worth naming unless the list is a single feed
+	showFeed := t.sources[t.source].feed == ""
+	for i := range lines {
+		row := t.postTop + i
+		if row >= len(t.posts) {
+			break
+		}
+		post := t.posts[row]
+		marker := "●"
+		if post.Read {
+			marker = " "
+		}
+		star := " "
+		if post.Starred {
+			star = "★"
+		}
+		text := fmt.Sprintf("%s%s %s  %s", marker, star, post.PublishedAt.Format("Jan 02"), singleLine(post.Title))
+		if showFeed {
+			text += "  · " + singleLine(post.FeedName)
+		}
+		lines[i] = drawLine(text, width, row == t.post, t.focus == panePosts)
+	}
+	return lines
+}
+
+// divider separates the post list from the reader, showing how far through
+// a long post the reader is.
+func (t *tui) divider(width int) string {
+	label := ""
+	if height := t.readerHeight(); len(t.reader) > height {
+		label = fmt.Sprintf(" %d%% ", min(100, 100*(t.readerTop+height)/len(t.reader)))
+	}
+	return term.Dim + strings.Repeat("─", max(0, width-len(label))) + label + term.Reset
+}
+
+func (t *tui) drawReader(width, height int) []string {
+	post, ok := t.selectedPost()
+	if !ok {
+		t.reader = nil
+	} else if post.ID != t.readerPost || width != t.readerWidth {
+		t.reader = renderPost(post, width-2)
+		t.readerPost, t.readerWidth = post.ID, width
+		t.readerTop = 0
+	}
+	t.readerTop = clampIndex(t.readerTop, max(len(t.reader)-height+1, 1))
+	lines := make([]string, height)
+	for i := range lines {
+		row := t.readerTop + i
+		if row < len(t.reader) {
+			lines[i] = term.Fit(" "+t.reader[row], width)
+		} else {
+			lines[i] = strings.Repeat(" ", width)
+		}
+	}
+	return lines
+}
+
+func (t *tui) drawStatus(width int) string {
+	left := t.message
+	if left == "" {
+		left = tuiHints
+	}
+	unread := 0
+	for _, post := range t.posts {
+		if !post.Read {
+			unread++
+		}
+	}
+	right := fmt.Sprintf("%s · %d unread of %d ", t.user.Name, unread, len(t.posts))
+	left = term.Fit(" "+left, max(0, width-term.Width(right)))
+	return term.Reverse + left + term.Fit(right, width-term.Width(left)) + term.Reset
+}
+
+// scrollTo moves the top line of a pane just enough to show the selection.
+func scrollTo(top, selected, height int) int {
+	if selected < top {
+		return selected
+	}
+	if selected >= top+height {
+		return selected - height + 1
+	}
+	return top
+}
+
+func singleLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// renderPost lays out a post for the reader pane: its title, where and when
+// it was published, its link and then its content as text.
+func renderPost(post postquery.Post, width int) []string {
+	width = max(width, 10)
+	var lines []string
+	for _, line := range wrapText(singleLine(post.Title), width) {
+		lines = append(lines, term.Bold+line+term.Reset)
+	}
+	byline := post.FeedName + " · " + post.PublishedAt.Format("January 2, 2006 15:04")
+	if post.Author != "" {
+		byline = post.Author + " in " + byline
+	}
+	for _, line := range wrapText(byline, width) {
+		lines = append(lines, term.Dim+line+term.Reset)
+	}
+	if post.Url != "" {
+		lines = append(lines, term.Dim+post.Url+term.Reset)
+	}
+	lines = append(lines, "")
+
+	content := post.Content
+	if strings.TrimSpace(content) == "" {
+		content = post.Description
+	}
+	text := markup.Text(content, markup.TextOptions{Width: width, Base: postBase(post.Url), Styled: true})
+	return append(lines, strings.Split(text, "\n")...)
+}
+
+// wrapText breaks text into lines of at most width runes, keeping each
+// line's indentation on the lines it wraps onto.
+func wrapText(text string, width int) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		indent := paragraph[:len(paragraph)-len(strings.TrimLeft(paragraph, " "))]
+		words := strings.Fields(paragraph)
+		if len(words) == 0 {
+			lines = append(lines, "")
+			continue
+		}
+		line := indent
+		for _, word := range words {
+			if line != indent && len([]rune(line))+1+len([]rune(word)) > width {
+				lines = append(lines, line)
+				line = indent
+			}
+			if line != indent {
+				line += " "
+			}
+			// a word longer than the line is split across lines
+			for len([]rune(line))+len([]rune(word)) > width && len([]rune(word)) > width-len(indent) {
+				cut := max(1, width-len([]rune(line)))
+				lines = append(lines, line+string([]rune(word)[:cut]))
+				word = string([]rune(word)[cut:])
+				line = indent
+			}
+			line += word
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
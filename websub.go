@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jdwalkerzhere/gator/internal/database"
+	"github.com/jdwalkerzhere/gator/internal/websub"
+)
+
+const (
+	websubLease         = 7 * 24 * time.Hour
+	websubRenewInterval = time.Hour
+)
+
+func handlerWebSub(s *state, cmd command) error {
+	if err := cmd.parseNoFlags(); err != nil {
+		return err
+	}
+	if len(cmd.args) < 2 {
+		return fmt.Errorf("Insufficient arguments, please provide both a listen address and the public callback URL\n")
+	}
+	addr, callbackBase := cmd.args[0], strings.TrimSuffix(cmd.args[1], "/")
+
+	mux := http.NewServeMux()
+	mux.Handle("/websub/", &websub.Handler{
+		Lookup: func(ctx context.Context, id string) (websub.Subscription, error) {
+			sub, err := lookupWebSubSubscription(ctx, s, id)
+			if err != nil {
+				return websub.Subscription{}, err
+			}
+			return websub.Subscription{Topic: sub.TopicUrl, Secret: sub.Secret, Pending: sub.PendingMode.String}, nil
+		},
+		Verify: func(ctx context.Context, id, mode string, lease time.Duration) error {
+			return verifyWebSubSubscription(ctx, s, id, mode, lease)
+		},
+		Deliver: func(ctx context.Context, id string, body []byte) error {
+			return deliverWebSubContent(ctx, s, id, body)
+		},
+	})
+
+	server := &http.Server{Addr: addr, Handler: mux}
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.ListenAndServe()
+	}()
+	fmt.Printf("Listening for WebSub callbacks on [%s]\n", addr)
+
+	ticker := time.NewTicker(websubRenewInterval)
+	defer ticker.Stop()
+	renewWebSubSubscriptions(context.Background(), s, callbackBase)
+	for {
+		select {
+		case err := <-serverErr:
+			return err
+		case <-ticker.C:
+			renewWebSubSubscriptions(context.Background(), s, callbackBase)
+		}
+	}
+}
+
+func renewWebSubSubscriptions(ctx context.Context, s *state, callbackBase string) {
+	feeds, err := s.db.GetFeedsWithHub(ctx)
+	if err != nil {
+		fmt.Printf("Error loading feeds with a hub: %v\n", err)
+		return
+	}
+	for _, feed := range feeds {
+		err := subscribeWebSub(ctx, s, feed, callbackBase)
+		if err != nil {
+			fmt.Printf("Error subscribing to [%s]: %v\n", feed.Name, err)
+		}
+	}
+
+	// feeds that stopped naming a hub go back to being polled only
+	orphaned, err := s.db.GetOrphanedWebsubSubscriptions(ctx)
+	if err != nil {
+		fmt.Printf("Error loading subscriptions of feeds without a hub: %v\n", err)
+		return
+	}
+	for _, sub := range orphaned {
+		err := unsubscribeWebSub(ctx, s, sub, callbackBase)
+		if err != nil {
+			fmt.Printf("Error unsubscribing from [%s]: %v\n", sub.TopicUrl, err)
+		}
+	}
+}
+
+func subscribeWebSub(ctx context.Context, s *state, feed database.Feed, callbackBase string) error {
+	topic := feed.Url
+	if feed.HubTopic.Valid {
+		topic = feed.HubTopic.String
+	}
+	secret, err := newWebSubSecret()
+	if err != nil {
+		return err
+	}
+
+	timeNow := time.Now()
+	subParams := database.UpsertWebsubSubscriptionParams{
+		ID:        uuid.New(),
+		CreatedAt: timeNow,
+		UpdatedAt: timeNow,
+		FeedID:    feed.ID,
+		HubUrl:    feed.HubUrl.String,
+		TopicUrl:  topic,
+		Secret:    secret,
+	}
+	sub, err := s.db.UpsertWebsubSubscription(ctx, subParams)
+	if err != nil {
+		return err
+	}
+
+	// leases that outlive the next renewal pass are left alone
+	if sub.LeaseExpiresAt.Valid && sub.LeaseExpiresAt.Time.After(timeNow.Add(2*websubRenewInterval)) {
+		return nil
+	}
+
+	pendingParams := database.SetWebsubPendingParams{
+		ID:          sub.ID,
+		PendingMode: sql.NullString{String: websub.ModeSubscribe, Valid: true},
+	}
+	err = s.db.SetWebsubPending(ctx, pendingParams)
+	if err != nil {
+		return err
+	}
+	req := websub.Request{
+		Mode:     websub.ModeSubscribe,
+		Hub:      sub.HubUrl,
+		Topic:    sub.TopicUrl,
+		Callback: callbackBase + "/websub/" + sub.ID.String(),
+		Secret:   sub.Secret,
+		Lease:    websubLease,
+	}
+	return websub.Send(ctx, &http.Client{}, req)
+}
+
+func unsubscribeWebSub(ctx context.Context, s *state, sub database.WebsubSubscription, callbackBase string) error {
+	// the hub has already forgotten subscriptions it never verified or whose
+	// lease ran out
+	if !sub.LeaseExpiresAt.Valid || sub.LeaseExpiresAt.Time.Before(time.Now()) {
+		fmt.Printf("Dropped subscription to [%s]\n", sub.TopicUrl)
+		return s.db.DeleteWebsubSubscription(ctx, sub.ID)
+	}
+
+	pendingParams := database.SetWebsubPendingParams{
+		ID:          sub.ID,
+		PendingMode: sql.NullString{String: websub.ModeUnsubscribe, Valid: true},
+	}
+	err := s.db.SetWebsubPending(ctx, pendingParams)
+	if err != nil {
+		return err
+	}
+	req := websub.Request{
+		Mode:     websub.ModeUnsubscribe,
+		Hub:      sub.HubUrl,
+		Topic:    sub.TopicUrl,
+		Callback: callbackBase + "/websub/" + sub.ID.String(),
+	}
+	return websub.Send(ctx, &http.Client{}, req)
+}
+
+func newWebSubSecret() (string, error) {
+	secret := make([]byte, 32)
+	_, err := rand.Read(secret)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+func lookupWebSubSubscription(ctx context.Context, s *state, id string) (database.WebsubSubscription, error) {
+	subID, err := uuid.Parse(id)
+	if err != nil {
+		return database.WebsubSubscription{}, err
+	}
+	return s.db.GetWebsubSubscription(ctx, subID)
+}
+
+func verifyWebSubSubscription(ctx context.Context, s *state, id, mode string, lease time.Duration) error {
+	sub, err := lookupWebSubSubscription(ctx, s, id)
+	if err != nil {
+		return err
+	}
+
+	switch mode {
+	case websub.ModeSubscribe:
+		leaseParams := database.SetWebsubLeaseParams{
+			ID:             sub.ID,
+			LeaseExpiresAt: sql.NullTime{Time: time.Now().Add(lease), Valid: true},
+		}
+		fmt.Printf("Subscribed to [%s] until %s\n", sub.TopicUrl, leaseParams.LeaseExpiresAt.Time.Format(time.RFC1123))
+		return s.db.SetWebsubLease(ctx, leaseParams)
+	case websub.ModeUnsubscribe:
+		fmt.Printf("Unsubscribed from [%s]\n", sub.TopicUrl)
+		return s.db.DeleteWebsubSubscription(ctx, sub.ID)
+	default:
+		fmt.Printf("Hub [%s] denied subscription to [%s]\n", sub.HubUrl, sub.TopicUrl)
+		return s.db.SetWebsubLease(ctx, database.SetWebsubLeaseParams{ID: sub.ID})
+	}
+}
+
+func deliverWebSubContent(ctx context.Context, s *state, id string, body []byte) error {
+	sub, err := lookupWebSubSubscription(ctx, s, id)
+	if err != nil {
+		return err
+	}
+	feed, err := s.db.GetFeedByID(ctx, sub.FeedID)
+	if err != nil {
+		return err
+	}
+
+	// content that can't be read is dropped, as the hub redelivering it
+	// wouldn't help
+	rssFeed, err := parseFeed(body)
+	if err != nil {
+		fmt.Printf("Dropped content for [%s]: %v\n", feed.Name, err)
+		return nil
+	}
+	newPosts, err := savePosts(ctx, s, feed, rssFeed)
+	fmt.Printf("Received %d items (%d new) for [%s]\n", len(rssFeed.Channel.Item), newPosts, feed.Name)
+	return err
+}